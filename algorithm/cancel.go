@@ -0,0 +1,105 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// maxConcurrentCancellations bounds the number of cancel requests sent to Crystal at the same time.
+const maxConcurrentCancellations = 8
+
+// RunFilter selects the runs affected by bulk operations: runs of the listed algorithms, runs with the tag,
+// or runs of the listed algorithms with the tag when both are set. At least one of them is required.
+type RunFilter struct {
+	AlgorithmNames []string
+	Tag            string
+}
+
+// CancelReport holds the outcome of cancelling a single run.
+type CancelReport struct {
+	AlgorithmName string
+	RequestID     string
+	Response      string
+	Err           error
+}
+
+// CancelRuns cancels every active run matching the filter with the same initiator and reason.
+// Runs are cancelled concurrently; failures are reported per run and do not stop other cancellations.
+// The returned error is only set when the matching runs could not be resolved.
+func (s Service) CancelRuns(ctx context.Context, filter RunFilter, initiator string, reason string) ([]CancelReport, error) {
+	reports, err := s.activeRuns(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentCancellations)
+	for i := range reports {
+		if reports[i].Err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(report *CancelReport) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				report.Err = ctx.Err()
+				return
+			}
+			if err := ctx.Err(); err != nil {
+				report.Err = err
+				return
+			}
+			report.Response, report.Err = s.CancelRun(report.AlgorithmName, report.RequestID, initiator, reason)
+		}(&reports[i])
+	}
+	wg.Wait()
+
+	return reports, nil
+}
+
+// activeRuns resolves the unfinished runs matching the filter.
+func (s Service) activeRuns(filter RunFilter) ([]CancelReport, error) {
+	var reports []CancelReport
+	switch {
+	case filter.Tag == "" && len(filter.AlgorithmNames) == 0:
+		return nil, fmt.Errorf("run filter requires a tag or at least one algorithm name")
+	case len(filter.AlgorithmNames) == 0:
+		runs, err := s.RetrieveRunsByTag(filter.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving runs tagged %s: %w", filter.Tag, err)
+		}
+		for _, run := range runs {
+			if run.IsFinished() {
+				continue
+			}
+			report := CancelReport{AlgorithmName: run.AlgorithmName, RequestID: run.RequestID}
+			if run.AlgorithmName == "" {
+				report.Err = fmt.Errorf("run %s tagged %s has no algorithm name", run.RequestID, filter.Tag)
+			}
+			reports = append(reports, report)
+		}
+	default:
+		for _, algorithmName := range filter.AlgorithmNames {
+			var runs []RunResult
+			var err error
+			if filter.Tag == "" {
+				runs, err = s.RetrieveAlgorithmRuns(algorithmName)
+			} else {
+				runs, err = s.RetrieveRuns(algorithmName, filter.Tag)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error resolving runs of %s: %w", algorithmName, err)
+			}
+			for _, run := range runs {
+				if !run.IsFinished() {
+					reports = append(reports, CancelReport{AlgorithmName: algorithmName, RequestID: run.RequestID})
+				}
+			}
+		}
+	}
+	return reports, nil
+}
@@ -0,0 +1,132 @@
+package algorithm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCrystal serves run listings and records cancellations, failing those of run "broken".
+type fakeCrystal struct {
+	mu        sync.Mutex
+	cancelled []string
+}
+
+func (f *fakeCrystal) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/algorithm/v1/results/forecast/tags/backfill":
+		_, _ = w.Write([]byte(`[{"requestId": "f-1", "status": "RUNNING"}, {"requestId": "f-2", "status": "COMPLETED"}]`))
+	case "/algorithm/v1/results/forecast":
+		_, _ = w.Write([]byte(`[{"requestId": "f-1", "status": "RUNNING"}, {"requestId": "f-3", "status": "BUFFERED"}, {"requestId": "f-4", "status": "FAILED"}]`))
+	case "/algorithm/v1/results/tags/backfill":
+		_, _ = w.Write([]byte(`[{"algorithmName": "forecast", "requestId": "f-1", "status": "RUNNING"},
+			{"algorithmName": "pricing", "requestId": "broken", "status": "NEW"},
+			{"algorithmName": "pricing", "requestId": "p-2", "status": "CANCELLED"}]`))
+	default:
+		if !strings.HasPrefix(r.URL.Path, "/algorithm/v1/cancel/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["initiator"] != "on-call" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/broken") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		f.mu.Lock()
+		f.cancelled = append(f.cancelled, strings.TrimPrefix(r.URL.Path, "/algorithm/v1/cancel/"))
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	}
+}
+
+func TestCancelRuns(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name          string
+		filter        RunFilter
+		wantCancelled []string
+		wantFailed    []string
+		wantErr       bool
+	}{
+		{
+			name:          "Algorithm and tag",
+			filter:        RunFilter{AlgorithmNames: []string{"forecast"}, Tag: "backfill"},
+			wantCancelled: []string{"forecast/requests/f-1"},
+		},
+		{
+			name:          "Algorithm only",
+			filter:        RunFilter{AlgorithmNames: []string{"forecast"}},
+			wantCancelled: []string{"forecast/requests/f-1", "forecast/requests/f-3"},
+		},
+		{
+			name:          "Tag only",
+			filter:        RunFilter{Tag: "backfill"},
+			wantCancelled: []string{"forecast/requests/f-1"},
+			wantFailed:    []string{"broken"},
+		},
+		{name: "Empty filter", wantErr: true},
+		{name: "Unknown algorithm", filter: RunFilter{AlgorithmNames: []string{"unknown"}}, wantErr: true},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crystal := &fakeCrystal{}
+			server := httptest.NewServer(crystal)
+			defer server.Close()
+			service, err := New(Config{SchedulerURL: server.URL, APIVersion: "v1", GetTokenFunc: func() (string, error) { return "token", nil }})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reports, err := service.CancelRuns(context.Background(), tt.filter, "on-call", "test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CancelRuns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var failed []string
+			for _, report := range reports {
+				if report.Err != nil {
+					failed = append(failed, report.RequestID)
+				}
+			}
+			sort.Strings(crystal.cancelled)
+			if strings.Join(crystal.cancelled, ",") != strings.Join(tt.wantCancelled, ",") {
+				t.Errorf("cancelled = %v, want %v", crystal.cancelled, tt.wantCancelled)
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.wantFailed, ",") {
+				t.Errorf("failed reports = %v, want %v", failed, tt.wantFailed)
+			}
+			if len(reports) != len(tt.wantCancelled)+len(tt.wantFailed) {
+				t.Errorf("reports = %+v, finished runs must be skipped", reports)
+			}
+		})
+	}
+}
+
+func TestRetrieveRuns(t *testing.T) {
+	server := httptest.NewServer(&fakeCrystal{})
+	defer server.Close()
+	service, _ := New(Config{SchedulerURL: server.URL, APIVersion: "v1", GetTokenFunc: func() (string, error) { return "token", nil }})
+
+	runs, err := service.RetrieveRuns("forecast", "backfill")
+	if err != nil {
+		t.Fatalf("RetrieveRuns() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].RequestID != "f-1" || runs[0].IsFinished() || !runs[1].IsFinished() {
+		t.Errorf("RetrieveRuns() = %+v", runs)
+	}
+
+	tagged, err := service.RetrieveRunsByTag("backfill")
+	if err != nil || len(tagged) != 3 || tagged[1].AlgorithmName != "pricing" || tagged[1].Status != StatusNew {
+		t.Errorf("RetrieveRunsByTag() = %+v, %v", tagged, err)
+	}
+}
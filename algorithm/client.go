@@ -0,0 +1,18 @@
+package algorithm
+
+import "context"
+
+// Client is implemented by Service, so code depending on Crystal can be tested with a mock, see the mocks package.
+type Client interface {
+	CreateRun(algorithmName string, input Payload, tag string) (string, error)
+	CreateRunFromTemplate(t Template, overrides map[string]interface{}, tag string) (string, error)
+	RetrieveRun(runID string, algorithmName string) (string, error)
+	RetrieveRuns(algorithmName string, tag string) ([]RunResult, error)
+	RetrieveRunsByTag(tag string) ([]TaggedRun, error)
+	RetrieveAlgorithmRuns(algorithmName string) ([]RunResult, error)
+	RetrievePayloadUri(runID string, algorithmName string) (*PayloadResponse, error)
+	CancelRun(algorithmName string, requestId string, initiator string, reason string) (string, error)
+	CancelRuns(ctx context.Context, filter RunFilter, initiator string, reason string) ([]CancelReport, error)
+}
+
+var _ Client = (*Service)(nil)
@@ -0,0 +1,210 @@
+package algorithm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	// quantityPattern matches Kubernetes resource quantities, i.e. 500m, 2, 1.5, 512Mi, 1e3.
+	quantityPattern = regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)(([KMGTPE]i)|[numkMGTPE]|[eE][+-]?[0-9]+)?$`)
+	// imageRepositoryPattern matches an OCI image reference without a tag or digest, i.e. registry.io:5000/team/image.
+	imageRepositoryPattern = regexp.MustCompile(`^(([a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*(:[0-9]+)?)/)?[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+	// imageTagPattern matches an OCI image tag.
+	imageTagPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+)
+
+// Validate checks the configuration for values Crystal would reject and returns all violations found.
+func (c CustomConfiguration) Validate() error {
+	var errs []error
+
+	if c.ImageRepository != nil && !imageRepositoryPattern.MatchString(*c.ImageRepository) {
+		errs = append(errs, fmt.Errorf("imageRepository %q is not a valid image reference", *c.ImageRepository))
+	}
+	if c.ImageTag != nil && !imageTagPattern.MatchString(*c.ImageTag) {
+		errs = append(errs, fmt.Errorf("imageTag %q is not a valid image tag", *c.ImageTag))
+	}
+	if c.DeadlineSeconds != nil && *c.DeadlineSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("deadlineSeconds must be positive, got %d", *c.DeadlineSeconds))
+	}
+	if c.MaximumRetries != nil && *c.MaximumRetries < 0 {
+		errs = append(errs, fmt.Errorf("maximumRetries must not be negative, got %d", *c.MaximumRetries))
+	}
+	if c.SpeculativeAttempts != nil && *c.SpeculativeAttempts < 0 {
+		errs = append(errs, fmt.Errorf("speculativeAttempts must not be negative, got %d", *c.SpeculativeAttempts))
+	}
+	if c.CpuLimit != nil && !quantityPattern.MatchString(*c.CpuLimit) {
+		errs = append(errs, fmt.Errorf("cpuLimit %q is not a valid Kubernetes quantity", *c.CpuLimit))
+	}
+	if c.MemoryLimit != nil && !quantityPattern.MatchString(*c.MemoryLimit) {
+		errs = append(errs, fmt.Errorf("memoryLimit %q is not a valid Kubernetes quantity", *c.MemoryLimit))
+	}
+	if c.Workgroup != nil && *c.Workgroup == "" {
+		errs = append(errs, errors.New("workgroup must not be empty when set"))
+	}
+	errs = append(errs, validateEntries("env", c.Env)...)
+	errs = append(errs, validateEntries("args", c.Args)...)
+
+	return errors.Join(errs...)
+}
+
+// validateEntries checks that every entry is named, uniquely named and carries a value consistent with its type.
+func validateEntries(field string, entries []ConfigurationEntry) []error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, e := range entries {
+		if e.Name == "" {
+			errs = append(errs, fmt.Errorf("%s[%d]: name is required", field, i))
+			continue
+		}
+		if seen[e.Name] {
+			errs = append(errs, fmt.Errorf("%s[%d]: duplicate name %q", field, i, e.Name))
+		}
+		seen[e.Name] = true
+
+		if e.ValueType == nil {
+			continue
+		}
+		switch *e.ValueType {
+		case PLAIN:
+		case RELATIVE_REFERENCE:
+			if e.Value == "" {
+				errs = append(errs, fmt.Errorf("%s[%d]: %s entry %q must reference a value", field, i, RELATIVE_REFERENCE, e.Name))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s[%d]: unknown value type %q for %q", field, i, *e.ValueType, e.Name))
+		}
+	}
+	return errs
+}
+
+// CustomConfigurationBuilder assembles a CustomConfiguration without having to take addresses of literals.
+type CustomConfigurationBuilder struct {
+	config CustomConfiguration
+}
+
+// NewCustomConfiguration starts building an empty CustomConfiguration.
+func NewCustomConfiguration() *CustomConfigurationBuilder {
+	return &CustomConfigurationBuilder{}
+}
+
+// WithImageRepository overrides the image repository used for the run.
+func (b *CustomConfigurationBuilder) WithImageRepository(repository string) *CustomConfigurationBuilder {
+	b.config.ImageRepository = &repository
+	return b
+}
+
+// WithImageTag overrides the image tag used for the run.
+func (b *CustomConfigurationBuilder) WithImageTag(tag string) *CustomConfigurationBuilder {
+	b.config.ImageTag = &tag
+	return b
+}
+
+// WithDeadlineSeconds sets the time after which the run is terminated.
+func (b *CustomConfigurationBuilder) WithDeadlineSeconds(seconds int) *CustomConfigurationBuilder {
+	b.config.DeadlineSeconds = &seconds
+	return b
+}
+
+// WithMaximumRetries sets how many times a failed run is retried.
+func (b *CustomConfigurationBuilder) WithMaximumRetries(retries int) *CustomConfigurationBuilder {
+	b.config.MaximumRetries = &retries
+	return b
+}
+
+// WithEnv adds a plain environment variable.
+func (b *CustomConfigurationBuilder) WithEnv(name string, value string) *CustomConfigurationBuilder {
+	b.config.Env = append(b.config.Env, newEntry(name, value, PLAIN))
+	return b
+}
+
+// WithEnvReference adds an environment variable resolved from a relative reference.
+func (b *CustomConfigurationBuilder) WithEnvReference(name string, reference string) *CustomConfigurationBuilder {
+	b.config.Env = append(b.config.Env, newEntry(name, reference, RELATIVE_REFERENCE))
+	return b
+}
+
+// WithSecret mounts an additional secret into the run.
+func (b *CustomConfigurationBuilder) WithSecret(secret string) *CustomConfigurationBuilder {
+	b.config.Secrets = append(b.config.Secrets, secret)
+	return b
+}
+
+// WithArg adds a plain argument.
+func (b *CustomConfigurationBuilder) WithArg(name string, value string) *CustomConfigurationBuilder {
+	b.config.Args = append(b.config.Args, newEntry(name, value, PLAIN))
+	return b
+}
+
+// WithArgReference adds an argument resolved from a relative reference.
+func (b *CustomConfigurationBuilder) WithArgReference(name string, reference string) *CustomConfigurationBuilder {
+	b.config.Args = append(b.config.Args, newEntry(name, reference, RELATIVE_REFERENCE))
+	return b
+}
+
+// WithCpuLimit sets the CPU limit as a Kubernetes quantity, i.e. 500m or 2.
+func (b *CustomConfigurationBuilder) WithCpuLimit(limit string) *CustomConfigurationBuilder {
+	b.config.CpuLimit = &limit
+	return b
+}
+
+// WithMemoryLimit sets the memory limit as a Kubernetes quantity, i.e. 512Mi or 2Gi.
+func (b *CustomConfigurationBuilder) WithMemoryLimit(limit string) *CustomConfigurationBuilder {
+	b.config.MemoryLimit = &limit
+	return b
+}
+
+// WithWorkgroup sets the workgroup the run is scheduled in.
+func (b *CustomConfigurationBuilder) WithWorkgroup(workgroup string) *CustomConfigurationBuilder {
+	b.config.Workgroup = &workgroup
+	return b
+}
+
+// WithAdditionalWorkgroup adds a workgroup the run may be scheduled in.
+func (b *CustomConfigurationBuilder) WithAdditionalWorkgroup(key string, workgroup string) *CustomConfigurationBuilder {
+	if b.config.AdditionalWorkgroups == nil {
+		b.config.AdditionalWorkgroups = make(map[string]string)
+	}
+	b.config.AdditionalWorkgroups[key] = workgroup
+	return b
+}
+
+// WithVersion pins the algorithm version.
+func (b *CustomConfigurationBuilder) WithVersion(version string) *CustomConfigurationBuilder {
+	b.config.Version = &version
+	return b
+}
+
+// WithMonitoringParameter adds a parameter to be reported to monitoring.
+func (b *CustomConfigurationBuilder) WithMonitoringParameter(parameter string) *CustomConfigurationBuilder {
+	b.config.MonitoringParameters = append(b.config.MonitoringParameters, parameter)
+	return b
+}
+
+// WithCustomResource requests a custom resource for the run.
+func (b *CustomConfigurationBuilder) WithCustomResource(name string, value string) *CustomConfigurationBuilder {
+	if b.config.CustomResources == nil {
+		b.config.CustomResources = make(map[string]string)
+	}
+	b.config.CustomResources[name] = value
+	return b
+}
+
+// WithSpeculativeAttempts sets the number of speculative attempts.
+func (b *CustomConfigurationBuilder) WithSpeculativeAttempts(attempts int) *CustomConfigurationBuilder {
+	b.config.SpeculativeAttempts = &attempts
+	return b
+}
+
+// Build validates and returns the assembled configuration.
+func (b *CustomConfigurationBuilder) Build() (CustomConfiguration, error) {
+	if err := b.config.Validate(); err != nil {
+		return CustomConfiguration{}, fmt.Errorf("invalid custom configuration: %w", err)
+	}
+	return b.config, nil
+}
+
+func newEntry(name string, value string, valueType ConfigurationValueType) ConfigurationEntry {
+	return ConfigurationEntry{Name: name, Value: value, ValueType: &valueType}
+}
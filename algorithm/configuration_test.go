@@ -0,0 +1,101 @@
+package algorithm
+
+import (
+	"testing"
+)
+
+func TestCustomConfigurationValidate(t *testing.T) {
+	unknown := ConfigurationValueType("SECRET")
+
+	// Define test cases
+	tests := []struct {
+		name    string
+		config  CustomConfiguration
+		wantErr bool
+	}{
+		{
+			name:    "Empty configuration",
+			config:  CustomConfiguration{},
+			wantErr: false,
+		},
+		{
+			name: "Valid configuration",
+			config: mustBuild(t, NewCustomConfiguration().
+				WithImageRepository("registry.example.com:5000/team/algorithm").
+				WithImageTag("v1.2.3").
+				WithDeadlineSeconds(3600).
+				WithMaximumRetries(0).
+				WithCpuLimit("500m").
+				WithMemoryLimit("2Gi").
+				WithEnv("MODE", "batch").
+				WithEnvReference("SOURCE", "input/path")),
+			wantErr: false,
+		},
+		{
+			name:    "Invalid cpu quantity",
+			config:  CustomConfiguration{CpuLimit: ptr("two cores")},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid memory quantity",
+			config:  CustomConfiguration{MemoryLimit: ptr("2GB")},
+			wantErr: true,
+		},
+		{
+			name:    "Non-positive deadline",
+			config:  CustomConfiguration{DeadlineSeconds: ptr(0)},
+			wantErr: true,
+		},
+		{
+			name:    "Negative retries",
+			config:  CustomConfiguration{MaximumRetries: ptr(-1)},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid image repository",
+			config:  CustomConfiguration{ImageRepository: ptr("Team/Algorithm")},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown value type",
+			config:  CustomConfiguration{Env: []ConfigurationEntry{{Name: "A", Value: "b", ValueType: &unknown}}},
+			wantErr: true,
+		},
+		{
+			name:    "Empty relative reference",
+			config:  unvalidated(NewCustomConfiguration().WithArgReference("A", "")),
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate env names",
+			config:  unvalidated(NewCustomConfiguration().WithEnv("A", "1").WithEnv("A", "2")),
+			wantErr: true,
+		},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func mustBuild(t *testing.T, b *CustomConfigurationBuilder) CustomConfiguration {
+	c, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	return c
+}
+
+func unvalidated(b *CustomConfigurationBuilder) CustomConfiguration {
+	return b.config
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
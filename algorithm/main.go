@@ -4,6 +4,7 @@ package algorithm
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/environment"
 	"github.com/SneaksAndData/esd-services-api-client-go/shared/httpclient"
 	"github.com/go-playground/validator/v10"
 	"net/http"
@@ -60,9 +61,43 @@ type PayloadResponse struct {
 	PayloadUri string `json:"payloadUri"`
 }
 
+// Run lifecycle stages reported by Crystal.
+const (
+	StatusNew               = "NEW"
+	StatusBuffered          = "BUFFERED"
+	StatusRunning           = "RUNNING"
+	StatusCompleted         = "COMPLETED"
+	StatusFailed            = "FAILED"
+	StatusSchedulingTimeout = "SCHEDULING_TIMEOUT"
+	StatusDeadlineExceeded  = "DEADLINE_EXCEEDED"
+	StatusThrottled         = "THROTTLED"
+	StatusCancelled         = "CANCELLED"
+)
+
+var finishedStatuses = map[string]bool{
+	StatusCompleted:         true,
+	StatusFailed:            true,
+	StatusSchedulingTimeout: true,
+	StatusDeadlineExceeded:  true,
+	StatusCancelled:         true,
+}
+
+// RunResult describes the state of a single algorithm run.
+type RunResult struct {
+	RequestID       string `json:"requestId"`
+	Status          string `json:"status"`
+	ResultUri       string `json:"resultUri"`
+	RunErrorMessage string `json:"runErrorMessage"`
+}
+
+// IsFinished reports whether the run has reached a terminal stage.
+func (r RunResult) IsFinished() bool {
+	return finishedStatuses[r.Status]
+}
+
 // RetrieveRun fetches the results of a specific algorithm run identified by runID.
 func (s Service) RetrieveRun(runID string, algorithmName string) (string, error) {
-	targetURL := fmt.Sprintf("%s/algorithm/%s/results/%s/requests/%s", s.schedulerURL, s.apiVersion, algorithmName, runID)
+	targetURL := httpclient.NewURL(s.schedulerURL).Path("algorithm", s.apiVersion, "results", algorithmName, "requests", runID).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -70,9 +105,60 @@ func (s Service) RetrieveRun(runID string, algorithmName string) (string, error)
 	return string(response), nil
 }
 
+// RetrieveRuns fetches all runs of an algorithm submitted with the given tag.
+func (s Service) RetrieveRuns(algorithmName string, tag string) ([]RunResult, error) {
+	targetURL := httpclient.NewURL(s.schedulerURL).Path("algorithm", s.apiVersion, "results", algorithmName, "tags", tag).String()
+	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to %s: %w", targetURL, err)
+	}
+
+	var runs []RunResult
+	if err := json.Unmarshal(response, &runs); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return runs, nil
+}
+
+// TaggedRun is a run listed across algorithms, which carries the name of its algorithm.
+type TaggedRun struct {
+	AlgorithmName string `json:"algorithmName"`
+	RunResult
+}
+
+// RetrieveRunsByTag fetches the runs of all algorithms submitted with the given tag.
+func (s Service) RetrieveRunsByTag(tag string) ([]TaggedRun, error) {
+	targetURL := httpclient.NewURL(s.schedulerURL).Path("algorithm", s.apiVersion, "results", "tags", tag).String()
+	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to %s: %w", targetURL, err)
+	}
+
+	var runs []TaggedRun
+	if err := json.Unmarshal(response, &runs); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return runs, nil
+}
+
+// RetrieveAlgorithmRuns fetches all runs of an algorithm, regardless of their tag.
+func (s Service) RetrieveAlgorithmRuns(algorithmName string) ([]RunResult, error) {
+	targetURL := httpclient.NewURL(s.schedulerURL).Path("algorithm", s.apiVersion, "results", algorithmName).String()
+	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to %s: %w", targetURL, err)
+	}
+
+	var runs []RunResult
+	if err := json.Unmarshal(response, &runs); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return runs, nil
+}
+
 // RetrievePayloadUri fetches the payload URI of a specific algorithm run identified by runID.
 func (s Service) RetrievePayloadUri(runID string, algorithmName string) (*PayloadResponse, error) {
-	targetURL := fmt.Sprintf("%s/algorithm/%s/payload/%s/requests/%s", s.schedulerURL, s.apiVersion, algorithmName, runID)
+	targetURL := httpclient.NewURL(s.schedulerURL).Path("algorithm", s.apiVersion, "payload", algorithmName, "requests", runID).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -90,8 +176,11 @@ func (s Service) CreateRun(algorithmName string, input Payload, tag string) (str
 	if err := validator.New().Struct(input); err != nil {
 		return "", fmt.Errorf("Validation failed: %v\n", err)
 	}
+	if err := input.CustomConfiguration.Validate(); err != nil {
+		return "", fmt.Errorf("invalid custom configuration: %w", err)
+	}
 
-	targetURL := fmt.Sprintf("%s/algorithm/%s/run/%s", s.schedulerURL, s.apiVersion, algorithmName)
+	targetURL := httpclient.NewURL(s.schedulerURL).Path("algorithm", s.apiVersion, "run", algorithmName).String()
 
 	input.AlgorithmName = algorithmName
 	input.Tag = tag
@@ -104,7 +193,7 @@ func (s Service) CreateRun(algorithmName string, input Payload, tag string) (str
 
 // CancelRun cancels an ongoing algorithm run
 func (s Service) CancelRun(algorithmName string, requestId string, initiator string, reason string) (string, error) {
-	targetURL := fmt.Sprintf("%s/algorithm/%s/cancel/%s/requests/%s", s.schedulerURL, s.apiVersion, algorithmName, requestId)
+	targetURL := httpclient.NewURL(s.schedulerURL).Path("algorithm", s.apiVersion, "cancel", algorithmName, "requests", requestId).String()
 	payload := make(map[string]string)
 	payload["initiator"] = initiator
 	payload["reason"] = reason
@@ -118,17 +207,30 @@ func (s Service) CancelRun(algorithmName string, requestId string, initiator str
 // Config represents the configuration needed to create a new Service instance.
 type Config struct {
 	GetTokenFunc func() (string, error) // Function to retrieve authentication token
-	HTTPClient   *httpclient.Client     // HTTP client to be used by the Service
+	HTTPClient   *httpclient.Client     // Shared HTTP client, GetTokenFunc is ignored when it is set
 	SchedulerURL string                 // Base URL for the scheduler service
 	APIVersion   string                 // API version to be used in requests
+	Env          string                 // Environment whose Crystal URL and API version are used when not set, see the environment package
 }
 
 // New creates a new instance of the Service using the provided Config.
 func New(c Config) (*Service, error) {
+	schedulerURL, err := environment.Resolve(c.SchedulerURL, c.Env, func(p environment.Profile) string { return p.CrystalURL })
+	if err != nil {
+		return nil, err
+	}
+	schedulerURL, err = httpclient.NormalizeBaseURL(schedulerURL)
+	if err != nil {
+		return nil, err
+	}
+	apiVersion, err := environment.Resolve(c.APIVersion, c.Env, func(p environment.Profile) string { return p.CrystalAPIVersion })
+	if err != nil {
+		return nil, err
+	}
 	s := &Service{
-		httpClient:   httpclient.NewClient(c.GetTokenFunc),
-		schedulerURL: c.SchedulerURL,
-		apiVersion:   c.APIVersion,
+		httpClient:   httpclient.ClientOrNew(c.HTTPClient, c.GetTokenFunc),
+		schedulerURL: schedulerURL,
+		apiVersion:   apiVersion,
 	}
 	return s, nil
 }
@@ -0,0 +1,77 @@
+package algorithm
+
+import (
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/shared/file"
+)
+
+// Template is a reusable Crystal run definition, usually kept in a JSON or YAML file next to the model.
+type Template struct {
+	AlgorithmName       string                 `json:"algorithmName"`
+	AlgorithmParameters map[string]interface{} `json:"algorithmParameters"`
+	CustomConfiguration CustomConfiguration    `json:"customConfiguration"`
+}
+
+// LoadTemplate reads a run template from a JSON or YAML file, picking the format from the file extension.
+func LoadTemplate(path string) (*Template, error) {
+	var t Template
+	if err := file.ReadStructured(path, &t); err != nil {
+		return nil, fmt.Errorf("error loading template: %w", err)
+	}
+	if t.AlgorithmName == "" {
+		return nil, fmt.Errorf("template %s does not define algorithmName", path)
+	}
+	if err := t.CustomConfiguration.Validate(); err != nil {
+		return nil, fmt.Errorf("template %s has invalid custom configuration: %w", path, err)
+	}
+	return &t, nil
+}
+
+// Payload builds a run payload from the template, deep merging overrides into the default parameters.
+// Nested maps are merged key by key, any other override value replaces the default. The template itself is not modified.
+func (t Template) Payload(overrides map[string]interface{}) Payload {
+	return Payload{
+		AlgorithmParameters: mergeParameters(t.AlgorithmParameters, overrides),
+		AlgorithmName:       t.AlgorithmName,
+		CustomConfiguration: t.CustomConfiguration,
+	}
+}
+
+// CreateRunFromTemplate submits a run defined by the template, with parameters overridden at call time.
+func (s Service) CreateRunFromTemplate(t Template, overrides map[string]interface{}, tag string) (string, error) {
+	return s.CreateRun(t.AlgorithmName, t.Payload(overrides), tag)
+}
+
+// mergeParameters returns a deep copy of defaults with overrides merged on top.
+func mergeParameters(defaults map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults)+len(overrides))
+	for k, v := range defaults {
+		merged[k] = copyValue(v)
+	}
+	for k, v := range overrides {
+		overrideMap, isMap := v.(map[string]interface{})
+		defaultMap, wasMap := merged[k].(map[string]interface{})
+		if isMap && wasMap {
+			merged[k] = mergeParameters(defaultMap, overrideMap)
+			continue
+		}
+		merged[k] = copyValue(v)
+	}
+	return merged
+}
+
+// copyValue deep copies maps and slices decoded from JSON so that merged payloads do not share state with templates.
+func copyValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return mergeParameters(value, nil)
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, item := range value {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,56 @@
+package algorithm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTemplatePayload(t *testing.T) {
+	template := Template{
+		AlgorithmName: "forecast",
+		AlgorithmParameters: map[string]interface{}{
+			"horizon": 7,
+			"source":  map[string]interface{}{"path": "abfss://default", "format": "delta"},
+		},
+	}
+
+	got := template.Payload(map[string]interface{}{
+		"horizon": 14,
+		"source":  map[string]interface{}{"path": "abfss://override"},
+	})
+	want := map[string]interface{}{
+		"horizon": 14,
+		"source":  map[string]interface{}{"path": "abfss://override", "format": "delta"},
+	}
+	if !reflect.DeepEqual(got.AlgorithmParameters, want) {
+		t.Errorf("Payload() parameters = %v, want %v", got.AlgorithmParameters, want)
+	}
+	if template.AlgorithmParameters["source"].(map[string]interface{})["path"] != "abfss://default" {
+		t.Errorf("Payload() modified the template")
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "forecast.yaml")
+	content := `
+algorithmName: forecast
+algorithmParameters:
+  horizon: 7
+customConfiguration:
+  imageTag: v1.0.0
+  cpuLimit: 500m
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	if got.AlgorithmName != "forecast" || *got.CustomConfiguration.CpuLimit != "500m" || got.AlgorithmParameters["horizon"] != float64(7) {
+		t.Errorf("LoadTemplate() = %+v", got)
+	}
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// Sender delivers signed completion callbacks the same way Crystal does.
+// It is meant for tests and local runs of receivers.
+type Sender struct {
+	URL        string
+	Secret     string
+	HTTPClient *http.Client
+}
+
+// Send posts a signed completion to the receiver.
+func (s Sender) Send(c Completion) error {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshaling payload: %w", err)
+	}
+	request, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(SignatureHeader, signaturePrefix+hex.EncodeToString(Sign([]byte(s.Secret), body)))
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusAccepted {
+		respBody, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("HTTP request failed with status code: %d - %s", response.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// NewTestServer starts an in-process server for the handler and returns it with a Sender targeting it.
+// Callers must Close the server.
+func NewTestServer(h *Handler) (*httptest.Server, Sender) {
+	server := httptest.NewServer(h)
+	return server, Sender{URL: server.URL, Secret: string(h.secret), HTTPClient: server.Client()}
+}
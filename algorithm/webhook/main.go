@@ -0,0 +1,310 @@
+// Package webhook provides an HTTP receiver for Crystal run completion callbacks
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/algorithm"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body, prefixed with "sha256=".
+	SignatureHeader = "X-Crystal-Signature"
+	// SecretHeader carries the shared secret verbatim, for senders that cannot sign requests.
+	SecretHeader = "X-Crystal-Secret"
+
+	signaturePrefix    = "sha256="
+	maxBodyBytes       = 1 << 20
+	defaultDeliveryTTL = 24 * time.Hour
+	maxCompleted       = 10000
+)
+
+// Completion is the callback sent by Crystal when a run reaches a terminal stage.
+type Completion struct {
+	AlgorithmName string `json:"algorithmName"`
+	algorithm.RunResult
+}
+
+// HandlerFunc processes a verified completion. Returning an error makes the receiver answer
+// with 500 so that the sender can retry the delivery.
+type HandlerFunc func(Completion) error
+
+// Handler verifies, decodes and dispatches Crystal completion callbacks.
+type Handler struct {
+	secret      []byte
+	deliveryTTL time.Duration
+	now         func() time.Time
+	mu          sync.Mutex
+	nextID      uint64
+	byAlgorithm map[string][]subscription
+	byRequest   map[string]subscription
+	// pending tracks deliveries that failed part way, so that a retry only calls the handlers that have not succeeded yet.
+	pending map[string]*delivery
+	// completed records deliveries that reached every handler, oldest first, so that redeliveries are acknowledged without dispatch.
+	completed      map[string]time.Time
+	completedOrder []string
+}
+
+type subscription struct {
+	id uint64
+	fn HandlerFunc
+}
+
+type delivery struct {
+	done     map[uint64]bool
+	inFlight bool
+	updated  time.Time
+}
+
+// Config holds the configuration needed to create a new Handler.
+type Config struct {
+	Secret      string        // Secret shared with Crystal, used to verify callbacks
+	DeliveryTTL time.Duration // How long delivered and partially failed completions are remembered, defaults to 24h
+}
+
+// New creates a Handler verifying callbacks with the configured secret.
+func New(c Config) (*Handler, error) {
+	if c.Secret == "" {
+		return nil, fmt.Errorf("webhook secret is required")
+	}
+	if c.DeliveryTTL <= 0 {
+		c.DeliveryTTL = defaultDeliveryTTL
+	}
+	return &Handler{
+		secret:      []byte(c.Secret),
+		deliveryTTL: c.DeliveryTTL,
+		now:         time.Now,
+		byAlgorithm: make(map[string][]subscription),
+		byRequest:   make(map[string]subscription),
+		pending:     make(map[string]*delivery),
+		completed:   make(map[string]time.Time),
+	}, nil
+}
+
+// OnAlgorithm registers fn for every completion of the given algorithm and returns a func removing it.
+func (h *Handler) OnAlgorithm(algorithmName string, fn HandlerFunc) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID++
+	id := h.nextID
+	h.byAlgorithm[algorithmName] = append(h.byAlgorithm[algorithmName], subscription{id: id, fn: fn})
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		subscriptions := h.byAlgorithm[algorithmName]
+		for i, sub := range subscriptions {
+			if sub.id == id {
+				h.byAlgorithm[algorithmName] = append(subscriptions[:i:i], subscriptions[i+1:]...)
+				break
+			}
+		}
+		if len(h.byAlgorithm[algorithmName]) == 0 {
+			delete(h.byAlgorithm, algorithmName)
+		}
+	}
+}
+
+// OnRequest registers fn for the completion of a single run and returns a func removing it.
+// It replaces any handler registered for the same run and is removed after the first successful call.
+func (h *Handler) OnRequest(requestID string, fn HandlerFunc) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID++
+	id := h.nextID
+	h.byRequest[requestID] = subscription{id: id, fn: fn}
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.byRequest[requestID].id == id {
+			delete(h.byRequest, requestID)
+		}
+	}
+}
+
+// AlgorithmChannel returns a channel receiving every completion of the given algorithm, and a func unsubscribing it.
+// Deliveries are rejected with 500 while the buffer is full. The channel is not closed on unsubscribe.
+func (h *Handler) AlgorithmChannel(algorithmName string, buffer int) (<-chan Completion, func()) {
+	ch := make(chan Completion, buffer)
+	return ch, h.OnAlgorithm(algorithmName, channelHandler(ch))
+}
+
+// Await returns a channel receiving the completion of a single run, and a func to stop waiting for it.
+func (h *Handler) Await(requestID string) (<-chan Completion, func()) {
+	ch := make(chan Completion, 1)
+	return ch, h.OnRequest(requestID, channelHandler(ch))
+}
+
+func channelHandler(ch chan Completion) HandlerFunc {
+	return func(c Completion) error {
+		select {
+		case ch <- c:
+			return nil
+		default:
+			return fmt.Errorf("subscriber for %s is not keeping up", c.AlgorithmName)
+		}
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return
+	}
+	if !h.verify(r.Header, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var completion Completion
+	if err := json.Unmarshal(body, &completion); err != nil {
+		http.Error(w, fmt.Sprintf("error unmarshaling callback: %v", err), http.StatusBadRequest)
+		return
+	}
+	if completion.AlgorithmName == "" || completion.RequestID == "" {
+		http.Error(w, "algorithmName and requestId are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(completion); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// verify accepts requests either signed with the secret or carrying it verbatim.
+func (h *Handler) verify(header http.Header, body []byte) bool {
+	if signature := header.Get(SignatureHeader); signature != "" {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+		if err != nil {
+			return false
+		}
+		return hmac.Equal(decoded, Sign(h.secret, body))
+	}
+	if secret := header.Get(SecretHeader); secret != "" {
+		return subtle.ConstantTimeCompare([]byte(secret), h.secret) == 1
+	}
+	return false
+}
+
+// dispatch calls the request handler first, then every algorithm handler, and returns the first error.
+// Handlers that succeeded are not called again when the sender retries a failed delivery,
+// and a delivery arriving while the same completion is still being dispatched is rejected.
+// A completion delivered within the delivery TTL is acknowledged without calling any handler.
+func (h *Handler) dispatch(c Completion) error {
+	key := c.AlgorithmName + "\x00" + c.RequestID
+
+	h.mu.Lock()
+	h.expire()
+	if _, ok := h.completed[key]; ok {
+		h.mu.Unlock()
+		return nil
+	}
+	d, ok := h.pending[key]
+	if !ok {
+		d = &delivery{done: make(map[uint64]bool)}
+		h.pending[key] = d
+	}
+	d.updated = h.now()
+	if d.inFlight {
+		h.mu.Unlock()
+		return fmt.Errorf("completion of request %s is already being dispatched", c.RequestID)
+	}
+	d.inFlight = true
+	requestHandler, hasRequestHandler := h.byRequest[c.RequestID]
+	hasRequestHandler = hasRequestHandler && !d.done[requestHandler.id]
+	var algorithmHandlers []subscription
+	for _, sub := range h.byAlgorithm[c.AlgorithmName] {
+		if !d.done[sub.id] {
+			algorithmHandlers = append(algorithmHandlers, sub)
+		}
+	}
+	h.mu.Unlock()
+
+	var succeeded []uint64
+	err := func() error {
+		if hasRequestHandler {
+			if err := requestHandler.fn(c); err != nil {
+				return fmt.Errorf("handler for request %s failed: %w", c.RequestID, err)
+			}
+			succeeded = append(succeeded, requestHandler.id)
+		}
+		for _, sub := range algorithmHandlers {
+			if err := sub.fn(c); err != nil {
+				return fmt.Errorf("handler for algorithm %s failed: %w", c.AlgorithmName, err)
+			}
+			succeeded = append(succeeded, sub.id)
+		}
+		return nil
+	}()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	d.inFlight = false
+	d.updated = h.now()
+	for _, id := range succeeded {
+		d.done[id] = true
+	}
+	if hasRequestHandler && d.done[requestHandler.id] && h.byRequest[c.RequestID].id == requestHandler.id {
+		delete(h.byRequest, c.RequestID)
+	}
+	if err == nil {
+		delete(h.pending, key)
+		h.completed[key] = d.updated
+		h.completedOrder = append(h.completedOrder, key)
+		h.expire()
+	}
+	return err
+}
+
+// expire forgets partially failed deliveries that were not retried within the delivery TTL,
+// and the oldest completed deliveries once they are older than the TTL or exceed maxCompleted.
+// It must be called with h.mu held.
+func (h *Handler) expire() {
+	cutoff := h.now().Add(-h.deliveryTTL)
+	for key, d := range h.pending {
+		if !d.inFlight && d.updated.Before(cutoff) {
+			delete(h.pending, key)
+		}
+	}
+	for len(h.completedOrder) > 0 {
+		key := h.completedOrder[0]
+		if len(h.completedOrder) <= maxCompleted && !h.completed[key].Before(cutoff) {
+			break
+		}
+		delete(h.completed, key)
+		h.completedOrder = h.completedOrder[1:]
+	}
+}
+
+// Sign returns the HMAC-SHA256 of body keyed with secret.
+func Sign(secret []byte, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"errors"
+	"github.com/SneaksAndData/esd-services-api-client-go/algorithm"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerDispatch(t *testing.T) {
+	handler, err := New(Config{Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	completions, _ := handler.AlgorithmChannel("forecast", 2)
+	awaited, _ := handler.Await("request-1")
+
+	server, sender := NewTestServer(handler)
+	defer server.Close()
+
+	completion := Completion{
+		AlgorithmName: "forecast",
+		RunResult:     algorithm.RunResult{RequestID: "request-1", Status: algorithm.StatusCompleted},
+	}
+	if err := sender.Send(completion); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if got := <-awaited; got != completion {
+		t.Errorf("Await() = %+v, want %+v", got, completion)
+	}
+	if got := <-completions; got != completion {
+		t.Errorf("AlgorithmChannel() = %+v, want %+v", got, completion)
+	}
+}
+
+func TestHandlerRejectsInvalidSignature(t *testing.T) {
+	handler, err := New(Config{Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, sender := NewTestServer(handler)
+	defer server.Close()
+
+	sender.Secret = "other"
+	err = sender.Send(Completion{AlgorithmName: "forecast", RunResult: algorithm.RunResult{RequestID: "request-1"}})
+	if err == nil {
+		t.Errorf("Send() with invalid signature succeeded")
+	}
+}
+
+func TestHandlerRetriesOnlyFailedHandlers(t *testing.T) {
+	handler, err := New(Config{Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := map[string]int{}
+	handler.OnRequest("request-1", func(Completion) error {
+		calls["request"]++
+		return nil
+	})
+	handler.OnAlgorithm("forecast", func(Completion) error {
+		calls["first"]++
+		return nil
+	})
+	handler.OnAlgorithm("forecast", func(Completion) error {
+		calls["second"]++
+		if calls["second"] == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	server, sender := NewTestServer(handler)
+	defer server.Close()
+
+	completion := Completion{AlgorithmName: "forecast", RunResult: algorithm.RunResult{RequestID: "request-1"}}
+	if err := sender.Send(completion); err == nil {
+		t.Fatalf("Send() succeeded although a handler failed")
+	}
+	if err := sender.Send(completion); err != nil {
+		t.Fatalf("Send() retry error = %v", err)
+	}
+	if calls["request"] != 1 || calls["first"] != 1 || calls["second"] != 2 {
+		t.Errorf("handler calls = %v, want request and first once, second twice", calls)
+	}
+
+	// Once delivered, a redelivery is acknowledged without calling any handler
+	if err := sender.Send(completion); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if calls["request"] != 1 || calls["first"] != 1 || calls["second"] != 2 {
+		t.Errorf("handler calls = %v after a redelivery", calls)
+	}
+}
+
+func TestHandlerForgetsDeliveriesAfterTTL(t *testing.T) {
+	handler, err := New(Config{Secret: "secret", DeliveryTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	handler.now = func() time.Time { return now }
+	calls := 0
+	handler.OnAlgorithm("forecast", func(Completion) error {
+		calls++
+		if calls == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	server, sender := NewTestServer(handler)
+	defer server.Close()
+
+	failed := Completion{AlgorithmName: "forecast", RunResult: algorithm.RunResult{RequestID: "request-1"}}
+	if err := sender.Send(failed); err == nil {
+		t.Fatalf("Send() succeeded although the handler failed")
+	}
+	now = now.Add(2 * time.Hour)
+	delivered := Completion{AlgorithmName: "forecast", RunResult: algorithm.RunResult{RequestID: "request-2"}}
+	if err := sender.Send(delivered); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(handler.pending) != 0 {
+		t.Errorf("pending deliveries = %d, want the failed delivery evicted", len(handler.pending))
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := sender.Send(delivered); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("handler calls = %d, want a redelivery after the TTL to be dispatched again", calls)
+	}
+}
+
+func TestHandlerUnsubscribe(t *testing.T) {
+	handler, err := New(Config{Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	completions, unsubscribe := handler.AlgorithmChannel("forecast", 1)
+	awaited, cancel := handler.Await("request-1")
+	unsubscribe()
+	cancel()
+
+	server, sender := NewTestServer(handler)
+	defer server.Close()
+
+	// The full buffer of an unsubscribed channel no longer rejects deliveries
+	for i := 0; i < 2; i++ {
+		if err := sender.Send(Completion{AlgorithmName: "forecast", RunResult: algorithm.RunResult{RequestID: "request-1"}}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if len(completions) != 0 || len(awaited) != 0 {
+		t.Errorf("unsubscribed channels received %d and %d completions", len(completions), len(awaited))
+	}
+}
+
+func TestHandlerRejectsLargeBodies(t *testing.T) {
+	handler, err := New(Config{Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, _ := NewTestServer(handler)
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(strings.Repeat("a", maxBodyBytes+1)))
+	request.Header.Set(SecretHeader, "secret")
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", response.StatusCode, http.StatusRequestEntityTooLarge)
+	}
+}
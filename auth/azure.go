@@ -2,26 +2,101 @@ package auth
 
 import (
 	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"os"
 )
 
-func getAzureDefaultToken() (string, error) {
-	cred, err := getAzureCredentials()
+// defaultAzureScope is requested when no scopes are configured.
+const defaultAzureScope = "https://management.core.windows.net/.default"
+
+// AzureCredential selects how tokens are acquired from Azure AD.
+type AzureCredential string
+
+const (
+	AzureDefault           AzureCredential = "default"            // DefaultAzureCredential chain
+	AzureManagedIdentity   AzureCredential = "managed-identity"   // Managed identity, user-assigned when ClientID is set
+	AzureWorkloadIdentity  AzureCredential = "workload-identity"  // Workload identity federation on Kubernetes
+	AzureClientSecret      AzureCredential = "client-secret"      // Service principal with a client secret
+	AzureClientCertificate AzureCredential = "client-certificate" // Service principal with a client certificate
+	AzureCLI               AzureCredential = "azure-cli"          // Account logged in with the Azure CLI
+)
+
+// AzureConfig configures Azure AD token acquisition for the azuread provider.
+type AzureConfig struct {
+	Credential          AzureCredential // Credential to use, defaults to AzureDefault
+	Scopes              []string        // Scopes to request, defaults to the Azure management scope
+	TenantID            string          // Tenant to authenticate in, required for client secrets and certificates
+	ClientID            string          // Client ID of the service principal or user-assigned managed identity
+	ClientSecret        string          // Secret for AzureClientSecret
+	CertificatePath     string          // PEM or PKCS#12 certificate file for AzureClientCertificate
+	CertificatePassword string          // Password of the certificate file, if any
+	TokenFilePath       string          // Federated token file for AzureWorkloadIdentity, defaults to AZURE_FEDERATED_TOKEN_FILE
+}
+
+// azureTokenSource acquires tokens with a credential created once and reused for the lifetime of the Service.
+type azureTokenSource struct {
+	credential azcore.TokenCredential
+	scopes     []string
+}
+
+func newAzureTokenSource(c AzureConfig) (*azureTokenSource, error) {
+	credential, err := newAzureCredential(c)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("error creating %s azure credential: %w", c.Credential, err)
+	}
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{defaultAzureScope}
 	}
-	token, err := cred.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: []string{"https://management.core.windows.net/.default"}})
+	return &azureTokenSource{credential: credential, scopes: scopes}, nil
+}
+
+func (a *azureTokenSource) getToken() (string, error) {
+	token, err := a.credential.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: a.scopes})
 	if err != nil {
 		return "", err
 	}
 	return token.Token, nil
 }
 
-func getAzureCredentials() (*azidentity.DefaultAzureCredential, error) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, err
+func newAzureCredential(c AzureConfig) (azcore.TokenCredential, error) {
+	switch c.Credential {
+	case AzureDefault, "":
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{TenantID: c.TenantID})
+	case AzureManagedIdentity:
+		options := &azidentity.ManagedIdentityCredentialOptions{}
+		if c.ClientID != "" {
+			options.ID = azidentity.ClientID(c.ClientID)
+		}
+		return azidentity.NewManagedIdentityCredential(options)
+	case AzureWorkloadIdentity:
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientID:      c.ClientID,
+			TenantID:      c.TenantID,
+			TokenFilePath: c.TokenFilePath,
+		})
+	case AzureClientSecret:
+		return azidentity.NewClientSecretCredential(c.TenantID, c.ClientID, c.ClientSecret, nil)
+	case AzureClientCertificate:
+		certData, err := os.ReadFile(c.CertificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading certificate file: %w", err)
+		}
+		var password []byte
+		if c.CertificatePassword != "" {
+			password = []byte(c.CertificatePassword)
+		}
+		certs, key, err := azidentity.ParseCertificates(certData, password)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing certificate file: %w", err)
+		}
+		return azidentity.NewClientCertificateCredential(c.TenantID, c.ClientID, certs, key, nil)
+	case AzureCLI:
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: c.TenantID})
+	default:
+		return nil, fmt.Errorf("unsupported azure credential: %s", c.Credential)
 	}
-	return cred, nil
 }
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewAzureTokenSource(t *testing.T) {
+	invalidCertificate := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalidCertificate, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	servicePrincipal := AzureConfig{
+		Credential:   AzureClientSecret,
+		TenantID:     "00000000-0000-0000-0000-000000000000",
+		ClientID:     "11111111-1111-1111-1111-111111111111",
+		ClientSecret: "secret",
+	}
+	withScopes := servicePrincipal
+	withScopes.Scopes = []string{"api://boxer/.default"}
+
+	// Define test cases
+	tests := []struct {
+		name       string
+		config     AzureConfig
+		wantScopes []string
+		wantErr    string
+	}{
+		{name: "Default scopes", config: servicePrincipal, wantScopes: []string{defaultAzureScope}},
+		{name: "Configured scopes", config: withScopes, wantScopes: []string{"api://boxer/.default"}},
+		{name: "Unsupported credential", config: AzureConfig{Credential: "password"}, wantErr: "unsupported azure credential: password"},
+		{
+			name:    "Missing certificate file",
+			config:  AzureConfig{Credential: AzureClientCertificate, CertificatePath: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr: "error reading certificate file",
+		},
+		{
+			name:    "Invalid certificate file",
+			config:  AzureConfig{Credential: AzureClientCertificate, CertificatePath: invalidCertificate},
+			wantErr: "error parsing certificate file",
+		},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := newAzureTokenSource(tt.config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newAzureTokenSource() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newAzureTokenSource() error = %v", err)
+			}
+			if !reflect.DeepEqual(source.scopes, tt.wantScopes) {
+				t.Errorf("scopes = %v, want %v", source.scopes, tt.wantScopes)
+			}
+		})
+	}
+}
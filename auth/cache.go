@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/shared/file"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// cacheDirName is created in the user cache directory when CacheConfig.Dir is empty.
+	cacheDirName            = "esd-services-api-client-go"
+	defaultCacheLockTimeout = 10 * time.Second
+	cacheLockRetryInterval  = 50 * time.Millisecond
+)
+
+// CacheConfig configures the on-disk token cache, which lets short-lived processes reuse tokens across runs.
+// Only tokens with an exp claim are cached, and they are used until shortly before they expire.
+type CacheConfig struct {
+	Enabled     bool          // Enables the cache
+	Dir         string        // Directory of the cache files, defaults to esd-services-api-client-go in the user cache directory
+	LockTimeout time.Duration // Time to wait for another process refreshing the same token, defaults to 10 seconds
+}
+
+// tokenCache stores one file per key, written atomically with 0600 permissions.
+// A lock file per key ensures that only one process refreshes a token while the others wait for the result.
+type tokenCache struct {
+	dir         string
+	lockTimeout time.Duration
+	now         func() time.Time
+}
+
+type cacheEntry struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func newTokenCache(c CacheConfig) (*tokenCache, error) {
+	dir := c.Dir
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("error locating the user cache directory: %w", err)
+		}
+		dir = filepath.Join(userCacheDir, cacheDirName)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating token cache directory: %w", err)
+	}
+	lockTimeout := c.LockTimeout
+	if lockTimeout <= 0 {
+		lockTimeout = defaultCacheLockTimeout
+	}
+	return &tokenCache{dir: dir, lockTimeout: lockTimeout, now: time.Now}, nil
+}
+
+// cacheKey identifies a cached token by its kind and every setting that determines who it was issued to,
+// including secrets and the static token read from the environment. Only the hash of the key names the cache file.
+func cacheKey(kind string, c Config) string {
+	c.Cache = CacheConfig{}
+	if c.Static.Token == "" {
+		c.Static.Token = os.Getenv(staticTokenEnv(c.Static))
+	}
+	settings, _ := json.Marshal(c)
+	return kind + "\x00" + string(settings)
+}
+
+// wrap returns a token source that serves tokens from the cache while they are valid.
+func (c *tokenCache) wrap(key string, refresh func() (string, error)) func() (string, error) {
+	return func() (string, error) {
+		return c.getOrRefresh(key, refresh)
+	}
+}
+
+// getOrRefresh returns the cached token for key, calling refresh and storing its result when there is none.
+func (c *tokenCache) getOrRefresh(key string, refresh func() (string, error)) (string, error) {
+	path := c.path(key)
+	if token, ok := c.read(path); ok {
+		return token, nil
+	}
+
+	unlock, err := c.lock(path)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	// Another process may have refreshed the token while this one was waiting for the lock.
+	if token, ok := c.read(path); ok {
+		return token, nil
+	}
+
+	token, err := refresh()
+	if err != nil {
+		return "", err
+	}
+	if info, err := Inspect(token); err == nil && !info.ExpiresAt.IsZero() {
+		// Failing to write the cache only costs a refresh on the next run.
+		_ = c.write(path, cacheEntry{Token: token, ExpiresAt: info.ExpiresAt})
+	}
+	return token, nil
+}
+
+// invalidate removes the cached token for key, i.e. after it was rejected, so that the next call refreshes it.
+func (c *tokenCache) invalidate(key string) {
+	_ = os.Remove(c.path(key))
+}
+
+func (c *tokenCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *tokenCache) read(path string) (string, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil || entry.Token == "" {
+		return "", false
+	}
+	if ExpiresSoon(entry.ExpiresAt, c.now()) {
+		return "", false
+	}
+	return entry.Token, true
+}
+
+func (c *tokenCache) write(path string, entry cacheEntry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return file.WriteAtomic(path, content)
+}
+
+// lock creates the lock file of path, removing locks older than the lock timeout left behind by crashed processes.
+func (c *tokenCache) lock(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(c.lockTimeout)
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			lockFile.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("error locking token cache: %w", err)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > c.lockTimeout {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for token cache lock %s", lockPath)
+		}
+		time.Sleep(cacheLockRetryInterval)
+	}
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenCache(t *testing.T) {
+	var requests int32
+	boxer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(testJWT(time.Now().Add(time.Hour))))
+	}))
+	defer boxer.Close()
+
+	config := Config{
+		TokenURL: boxer.URL,
+		Provider: "static-local",
+		Static:   StaticConfig{Token: "local"},
+		Cache:    CacheConfig{Enabled: true, Dir: t.TempDir()},
+	}
+
+	// Services stand in for separate processes sharing the cache directory.
+	var wg sync.WaitGroup
+	tokens := make([]string, 5)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			service, err := New(config)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if tokens[i], err = service.GetBoxerToken(); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("Boxer requests = %d, want 1", requests)
+	}
+	for _, token := range tokens[1:] {
+		if token != tokens[0] {
+			t.Errorf("cached token = %q, want %q", token, tokens[0])
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(config.Cache.Dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("cache files = %v, want one", files)
+	}
+	if info, err := os.Stat(files[0]); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("cache file mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+
+	// Expired tokens are refreshed
+	service, _ := New(config)
+	service.cache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := service.GetBoxerToken(); err != nil || requests != 2 {
+		t.Errorf("expired token was not refreshed: requests = %d, err = %v", requests, err)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	base := Config{TokenURL: "https://boxer.example.com", Provider: "azuread", Azure: AzureConfig{TenantID: "tenant", ClientID: "client"}}
+	t.Setenv("TEST_CACHE_TOKEN", "first")
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		change func(c *Config)
+	}{
+		{name: "Scopes", change: func(c *Config) { c.Azure.Scopes = []string{"api://boxer/.default"} }},
+		{name: "Credential", change: func(c *Config) { c.Azure.Credential = AzureCLI }},
+		{name: "Client secret", change: func(c *Config) { c.Azure.ClientSecret = "secret" }},
+		{name: "Kubernetes token path", change: func(c *Config) { c.Kubernetes.TokenPath = "/var/run/secrets/tokens/boxer" }},
+		{name: "OIDC audience", change: func(c *Config) { c.OIDC.Audience = "boxer" }},
+		{name: "Static token", change: func(c *Config) { c.Static.Token = "token" }},
+		{name: "Static token from environment", change: func(c *Config) { c.Static.EnvVar = "TEST_CACHE_TOKEN" }},
+		{name: "Chain sources", change: func(c *Config) { c.Chain.Sources = []ChainSource{ChainEnv} }},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := base
+			tt.change(&changed)
+			if cacheKey("boxer", changed) == cacheKey("boxer", base) {
+				t.Errorf("cacheKey() does not depend on the changed setting")
+			}
+		})
+	}
+
+	withCache := base
+	withCache.Cache = CacheConfig{Enabled: true, Dir: t.TempDir()}
+	if cacheKey("boxer", withCache) != cacheKey("boxer", base) {
+		t.Errorf("cacheKey() depends on the cache settings")
+	}
+	fromEnv := base
+	fromEnv.Static.EnvVar = "TEST_CACHE_TOKEN"
+	key := cacheKey("boxer", fromEnv)
+	t.Setenv("TEST_CACHE_TOKEN", "second")
+	if cacheKey("boxer", fromEnv) == key {
+		t.Errorf("cacheKey() does not depend on the static token in the environment")
+	}
+}
+
+func TestTokenCacheInvalidation(t *testing.T) {
+	var issued int32
+	tokenEndpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&issued, 1)
+		_, _ = w.Write([]byte(`{"access_token": "` + testJWT(time.Now().Add(time.Duration(n)*time.Hour)) + `", "expires_in": 3600}`))
+	}))
+	defer tokenEndpoint.Close()
+	var first string
+	var boxerRequests int32
+	boxer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&boxerRequests, 1)
+		// Boxer rejects the first identity token, i.e. because it was revoked before it expired.
+		if first == "" {
+			first = r.Header.Get("Authorization")
+		}
+		if r.Header.Get("Authorization") == first {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(testJWT(time.Now().Add(time.Hour))))
+	}))
+	defer boxer.Close()
+
+	config := Config{
+		TokenURL: boxer.URL,
+		Provider: "oidc-partner",
+		OIDC:     OIDCConfig{TokenEndpoint: tokenEndpoint.URL, ClientID: "client"},
+		Cache:    CacheConfig{Enabled: true, Dir: t.TempDir()},
+	}
+	service, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boxerToken, err := service.GetBoxerToken()
+	if err != nil || issued != 2 {
+		t.Fatalf("GetBoxerToken() error = %v after %d identity tokens, want the rejected one to be replaced", err, issued)
+	}
+
+	// The Boxer token is served from the cache until it is invalidated
+	if token, err := service.GetBoxerToken(); err != nil || token != boxerToken || boxerRequests != 2 {
+		t.Errorf("GetBoxerToken() = %v, %v after %d Boxer requests, want the cached token", token, err, boxerRequests)
+	}
+	service.InvalidateBoxerToken()
+	if _, err := service.GetBoxerToken(); err != nil || boxerRequests != 3 || issued != 2 {
+		t.Errorf("GetBoxerToken() error = %v after %d Boxer requests, want a new Boxer token from the cached identity token", err, boxerRequests)
+	}
+}
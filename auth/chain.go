@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ChainSource is an identity token source tried by the chain provider.
+type ChainSource string
+
+const (
+	ChainKubernetes       ChainSource = "kubernetes"        // Kubernetes service account token
+	ChainWorkloadIdentity ChainSource = "workload-identity" // Azure workload identity federation
+	ChainAzureCLI         ChainSource = "azure-cli"         // Account logged in with the Azure CLI
+	ChainAzureDefault     ChainSource = "azure-default"     // DefaultAzureCredential chain
+	ChainEnv              ChainSource = "env"               // Token from an environment variable, see StaticConfig
+)
+
+// defaultChainSources is the order used when ChainConfig.Sources is empty.
+var defaultChainSources = []ChainSource{ChainKubernetes, ChainWorkloadIdentity, ChainAzureCLI, ChainAzureDefault, ChainEnv}
+
+// ChainConfig configures the chain provider, which uses the first source that returns a token.
+// Azure sources use the azuread Boxer provider and the settings in Config.Azure.
+//
+// The chain is resolved once, on the first token request, and the source found is kept for the lifetime of the
+// Service so that the Boxer provider does not change between requests. Later failures of that source are returned
+// as is, without falling back to the other sources; create a new Service to resolve the chain again.
+type ChainConfig struct {
+	Sources            []ChainSource // Sources to try in order, defaults to kubernetes, workload-identity, azure-cli, azure-default, env
+	KubernetesProvider string        // Boxer provider for the service account token, e.g. the cluster name
+	EnvProvider        string        // Boxer provider for the environment variable token
+}
+
+// chainLink is a token source together with the Boxer provider that accepts its tokens.
+type chainLink struct {
+	source   ChainSource
+	provider string
+	getToken func() (string, error)
+}
+
+// tokenChain resolves the first working source once and keeps using it, see ChainConfig.
+type tokenChain struct {
+	links    []chainLink
+	mu       sync.Mutex
+	resolved *chainLink
+	// pending is the token obtained while resolving, handed out on the next getToken call.
+	pending string
+}
+
+func newTokenChain(c Config) (*tokenChain, error) {
+	sources := c.Chain.Sources
+	if len(sources) == 0 {
+		sources = defaultChainSources
+	}
+	chain := &tokenChain{}
+	for _, source := range sources {
+		link := chainLink{source: source}
+		switch source {
+		case ChainKubernetes:
+			link.provider = c.Chain.KubernetesProvider
+			link.getToken = newKubernetesTokenSource(c.Kubernetes).getToken
+		case ChainWorkloadIdentity, ChainAzureCLI, ChainAzureDefault:
+			link.provider = "azuread"
+			link.getToken = lazyAzureTokenSource(c.Azure, chainAzureCredentials[source])
+		case ChainEnv:
+			link.provider = c.Chain.EnvProvider
+			link.getToken = newStaticTokenSource(StaticConfig{EnvVar: c.Static.EnvVar})
+		default:
+			return nil, fmt.Errorf("unsupported chain source: %s", source)
+		}
+		chain.links = append(chain.links, link)
+	}
+	return chain, nil
+}
+
+var chainAzureCredentials = map[ChainSource]AzureCredential{
+	ChainWorkloadIdentity: AzureWorkloadIdentity,
+	ChainAzureCLI:         AzureCLI,
+	ChainAzureDefault:     AzureDefault,
+}
+
+// lazyAzureTokenSource defers creating the credential, as workload identity fails to construct outside of a pod.
+func lazyAzureTokenSource(c AzureConfig, credential AzureCredential) func() (string, error) {
+	c.Credential = credential
+	return lazyTokenSource(func() (func() (string, error), error) {
+		source, err := newAzureTokenSource(c)
+		if err != nil {
+			return nil, err
+		}
+		return source.getToken, nil
+	})
+}
+
+// lazyTokenSource creates the token source on first use, retrying on every call until creation succeeds,
+// i.e. once the federated token file has been mounted.
+func lazyTokenSource(create func() (func() (string, error), error)) func() (string, error) {
+	var mu sync.Mutex
+	var getToken func() (string, error)
+	return func() (string, error) {
+		mu.Lock()
+		if getToken == nil {
+			created, err := create()
+			if err != nil {
+				mu.Unlock()
+				return "", err
+			}
+			getToken = created
+		}
+		mu.Unlock()
+		return getToken()
+	}
+}
+
+// resolve returns the link in use, trying every source in order on the first call.
+func (t *tokenChain) resolve() (*chainLink, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.resolved != nil {
+		return t.resolved, nil
+	}
+
+	var errs []error
+	for i := range t.links {
+		link := &t.links[i]
+		if link.provider == "" {
+			errs = append(errs, fmt.Errorf("%s: no Boxer provider configured", link.source))
+			continue
+		}
+		token, err := link.getToken()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", link.source, err))
+			continue
+		}
+		t.resolved, t.pending = link, token
+		return link, nil
+	}
+	return nil, fmt.Errorf("no token source in the chain succeeded: %w", errors.Join(errs...))
+}
+
+func (t *tokenChain) getToken() (string, error) {
+	link, err := t.resolve()
+	if err != nil {
+		return "", err
+	}
+	t.mu.Lock()
+	token := t.pending
+	t.pending = ""
+	t.mu.Unlock()
+	if token != "" {
+		return token, nil
+	}
+	return link.getToken()
+}
+
+// source returns the source that succeeded, or an empty string before the chain was resolved.
+func (t *tokenChain) source() ChainSource {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.resolved == nil {
+		return ""
+	}
+	return t.resolved.source
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	boxer := newBoxer()
+	defer boxer.Close()
+
+	config := Config{
+		TokenURL:   boxer.URL,
+		Provider:   "chain",
+		Kubernetes: KubernetesConfig{TokenPath: filepath.Join(t.TempDir(), "missing")},
+		Static:     StaticConfig{EnvVar: "TEST_CHAIN_TOKEN"},
+		Chain: ChainConfig{
+			Sources:            []ChainSource{ChainKubernetes, ChainEnv},
+			KubernetesProvider: "cluster",
+			EnvProvider:        "local",
+		},
+	}
+
+	// All sources fail
+	service, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, err = service.GetBoxerToken()
+	if err == nil || !strings.Contains(err.Error(), "kubernetes:") || !strings.Contains(err.Error(), "env:") {
+		t.Fatalf("GetBoxerToken() error = %v, want errors of all sources", err)
+	}
+	if service.Source() != "" {
+		t.Errorf("Source() = %q, want empty before resolution", service.Source())
+	}
+
+	// Falls back to the environment variable
+	t.Setenv("TEST_CHAIN_TOKEN", "developer")
+	got, err := service.GetBoxerToken()
+	if err != nil {
+		t.Fatalf("GetBoxerToken() error = %v", err)
+	}
+	if want := "/token/local Bearer developer"; got != want {
+		t.Errorf("GetBoxerToken() = %q, want %q", got, want)
+	}
+	if service.Source() != string(ChainEnv) {
+		t.Errorf("Source() = %q, want %q", service.Source(), ChainEnv)
+	}
+
+	config.Chain.Sources = []ChainSource{"unknown"}
+	if _, err := New(config); err == nil {
+		t.Errorf("New() with unknown source succeeded")
+	}
+}
+
+func TestLazyTokenSourceRetriesCreation(t *testing.T) {
+	attempts := 0
+	getToken := lazyTokenSource(func() (func() (string, error), error) {
+		attempts++
+		if attempts == 1 {
+			return nil, fmt.Errorf("federated token file is not mounted yet")
+		}
+		return func() (string, error) { return "token", nil }, nil
+	})
+
+	if _, err := getToken(); err == nil {
+		t.Fatalf("getToken() succeeded although creation failed")
+	}
+	for i := 0; i < 2; i++ {
+		if token, err := getToken(); err != nil || token != "token" {
+			t.Errorf("getToken() = %q, %v, want creation to be retried", token, err)
+		}
+	}
+	if attempts != 2 {
+		t.Errorf("creation attempts = %d, want 2", attempts)
+	}
+}
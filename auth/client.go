@@ -0,0 +1,11 @@
+package auth
+
+// Client is implemented by Service, so code depending on Boxer tokens can be tested with a mock, see the mocks package.
+type Client interface {
+	GetBoxerToken() (string, error)
+	InvalidateBoxerToken()
+	Source() string
+	Env() string
+}
+
+var _ Client = (*Service)(nil)
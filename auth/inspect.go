@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// BoxerClaimNamespace prefixes the claims Boxer embeds in its tokens.
+	BoxerClaimNamespace = "boxer.sneaksanddata.com/"
+	// tokenExpirySkew treats tokens as expired slightly early to absorb clock drift and request latency.
+	tokenExpirySkew = 30 * time.Second
+)
+
+// TokenHeader is the decoded JOSE header of a JWT.
+type TokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// TokenInfo describes a decoded JWT.
+type TokenInfo struct {
+	Header    TokenHeader
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time // Zero when the token has no exp claim
+	IssuedAt  time.Time
+	NotBefore time.Time
+	// BoxerClaims holds the claims in the Boxer namespace, keyed without the namespace prefix.
+	BoxerClaims map[string]string
+	// Claims holds every claim of the payload as decoded from JSON.
+	Claims map[string]interface{}
+	// Verified is set when the signature was checked by Verify.
+	Verified bool
+}
+
+// IsExpired reports whether the token has expired at the given time.
+func (t TokenInfo) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// ExpiresIn returns the time left until the token expires, or zero for tokens without an exp claim.
+func (t TokenInfo) ExpiresIn(now time.Time) time.Duration {
+	if t.ExpiresAt.IsZero() {
+		return 0
+	}
+	return t.ExpiresAt.Sub(now)
+}
+
+// ExpiresSoon reports whether a token expiring at expiresAt must be renewed before use at now,
+// i.e. it expires within 30s to absorb clock drift and request latency. A zero expiresAt always expires soon.
+func ExpiresSoon(expiresAt time.Time, now time.Time) bool {
+	return !now.Add(tokenExpirySkew).Before(expiresAt)
+}
+
+// BoxerClaimNames returns the names of the Boxer claims in alphabetical order.
+func (t TokenInfo) BoxerClaimNames() []string {
+	names := make([]string, 0, len(t.BoxerClaims))
+	for name := range t.BoxerClaims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Inspect decodes the header and claims of a JWT without verifying its signature.
+func Inspect(token string) (*TokenInfo, error) {
+	header, payload, _, err := splitJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &TokenInfo{BoxerClaims: map[string]string{}}
+	if err := decodeSegment(header, &info.Header); err != nil {
+		return nil, fmt.Errorf("error decoding JWT header: %w", err)
+	}
+	if err := decodeSegment(payload, &info.Claims); err != nil {
+		return nil, fmt.Errorf("error decoding JWT payload: %w", err)
+	}
+
+	info.Issuer, _ = info.Claims["iss"].(string)
+	info.Subject, _ = info.Claims["sub"].(string)
+	switch aud := info.Claims["aud"].(type) {
+	case string:
+		info.Audience = []string{aud}
+	case []interface{}:
+		for _, value := range aud {
+			if s, ok := value.(string); ok {
+				info.Audience = append(info.Audience, s)
+			}
+		}
+	}
+	for name, target := range map[string]*time.Time{"exp": &info.ExpiresAt, "iat": &info.IssuedAt, "nbf": &info.NotBefore} {
+		if *target, err = numericDate(info.Claims[name]); err != nil {
+			return nil, fmt.Errorf("invalid %s claim: %w", name, err)
+		}
+	}
+	for name, value := range info.Claims {
+		if strings.HasPrefix(name, BoxerClaimNamespace) {
+			info.BoxerClaims[strings.TrimPrefix(name, BoxerClaimNamespace)] = claimString(value)
+		}
+	}
+	return info, nil
+}
+
+// splitJWT returns the encoded header and payload, and the decoded signature.
+func splitJWT(token string) (string, string, []byte, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return "", "", nil, fmt.Errorf("token is not a JWT")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", "", nil, fmt.Errorf("error decoding JWT signature: %w", err)
+	}
+	return parts[0], parts[1], signature, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	content, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(content)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// numericDate converts a JWT NumericDate, seconds since the epoch, to a time.
+func numericDate(value interface{}) (time.Time, error) {
+	if value == nil {
+		return time.Time{}, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, fmt.Errorf("expected a number, got %v", value)
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	whole := int64(seconds)
+	return time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second))), nil
+}
+
+// claimString renders a claim value, keeping strings as is and encoding other values as JSON.
+func claimString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	content, _ := json.Marshal(value)
+	return string(content)
+}
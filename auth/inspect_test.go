@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// signES256 builds a JWT with the given payload signed by key.
+func signES256(t *testing.T, key *ecdsa.PrivateKey, payload string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	signed := encode([]byte(`{"alg":"ES256","kid":"key-1","typ":"JWT"}`)) + "." + encode([]byte(payload))
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return signed + "." + encode(signature)
+}
+
+func TestInspectAndVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	token := signES256(t, key, fmt.Sprintf(`{"iss":"boxer","sub":"user@example.com","aud":["spark","crystal"],"exp":%d,
+		"boxer.sneaksanddata.com/api-version":"v1","boxer.sneaksanddata.com/identity-provider":"azuread"}`, exp))
+
+	info, err := Inspect(token)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if info.Issuer != "boxer" || info.Subject != "user@example.com" || len(info.Audience) != 2 || info.ExpiresAt.Unix() != exp {
+		t.Errorf("Inspect() = %+v, want registered claims", info)
+	}
+	if info.BoxerClaims["identity-provider"] != "azuread" || len(info.BoxerClaimNames()) != 2 || info.Verified {
+		t.Errorf("Inspect() boxer claims = %v, verified = %v", info.BoxerClaims, info.Verified)
+	}
+
+	// Verify against a PEM key file
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := Verify(token, VerifyOptions{KeyFile: keyFile}); err != nil || !info.Verified {
+		t.Errorf("Verify() with key file error = %v", err)
+	}
+
+	// Verify against a JWKS endpoint
+	encode := base64.RawURLEncoding.EncodeToString
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"keys":[{"kty":"EC","kid":"key-1","use":"sig","crv":"P-256","x":%q,"y":%q}]}`,
+			encode(key.X.FillBytes(make([]byte, 32))), encode(key.Y.FillBytes(make([]byte, 32))))
+	}))
+	defer jwks.Close()
+	if _, err := Verify(token, VerifyOptions{JWKSURL: jwks.URL}); err != nil {
+		t.Errorf("Verify() with JWKS error = %v", err)
+	}
+
+	// Tokens signed by another key are rejected
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := Verify(signES256(t, other, `{"sub":"attacker"}`), VerifyOptions{JWKSURL: jwks.URL}); err == nil {
+		t.Errorf("Verify() accepted a token signed by another key")
+	}
+
+	if _, err := Inspect("not-a-token"); err == nil {
+		t.Errorf("Inspect() accepted a malformed token")
+	}
+}
+
+func TestExpiresSoon(t *testing.T) {
+	now := time.Now()
+	// Define test cases
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		want      bool
+	}{
+		{name: "Valid", expiresAt: now.Add(time.Hour), want: false},
+		{name: "Within skew", expiresAt: now.Add(10 * time.Second), want: true},
+		{name: "Expired", expiresAt: now.Add(-time.Second), want: true},
+		{name: "No expiry", want: true},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpiresSoon(tt.expiresAt, now); got != tt.want {
+				t.Errorf("ExpiresSoon() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
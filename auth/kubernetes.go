@@ -2,18 +2,75 @@ package auth
 
 import (
 	"fmt"
-	"github.com/SneaksAndData/esd-services-api-client-go/shared/file"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
-func getKubernetesToken() (string, error) {
-	tokenFilePath := "/var/run/secrets/kubernetes.io/serviceaccount/token"
-	if !file.FileExists(tokenFilePath) {
-		return "", fmt.Errorf("could not find token file at %s", tokenFilePath)
+// defaultKubernetesTokenPath is where Kubernetes mounts the service account token.
+const defaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// KubernetesConfig configures the service account token used by the k8s-* providers.
+type KubernetesConfig struct {
+	// TokenPath is the token file, i.e. a projected audience-scoped token. Defaults to the service account token.
+	TokenPath string
+}
+
+// kubernetesTokenSource reads the service account token, caching it until the file changes or the token expires.
+// Kubelet rotates projected tokens by replacing the file, which changes its modification time.
+type kubernetesTokenSource struct {
+	path    string
+	now     func() time.Time
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+	expiry  time.Time
+}
+
+func newKubernetesTokenSource(c KubernetesConfig) *kubernetesTokenSource {
+	path := c.TokenPath
+	if path == "" {
+		path = defaultKubernetesTokenPath
+	}
+	return &kubernetesTokenSource{path: path, now: time.Now}
+}
+
+func (k *kubernetesTokenSource) getToken() (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return "", fmt.Errorf("could not find token file at %s: %w", k.path, err)
+	}
+	if k.token != "" && info.ModTime().Equal(k.modTime) && info.Size() == k.size && !k.isExpired() {
+		return k.token, nil
 	}
-	token, err := os.ReadFile(tokenFilePath)
+
+	content, err := os.ReadFile(k.path)
 	if err != nil {
 		return "", fmt.Errorf("error reading token file: %w", err)
 	}
-	return string(token), nil
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file at %s is empty", k.path)
+	}
+	// Tokens that are not JWTs cannot be inspected and are cached until the file changes.
+	var expiry time.Time
+	if info, err := Inspect(token); err == nil {
+		expiry = info.ExpiresAt
+	}
+
+	k.token, k.modTime, k.size, k.expiry = token, info.ModTime(), info.Size(), expiry
+	if k.isExpired() {
+		k.token = ""
+		return "", fmt.Errorf("token at %s expired at %s and has not been rotated yet", k.path, expiry.Format(time.RFC3339))
+	}
+	return token, nil
+}
+
+func (k *kubernetesTokenSource) isExpired() bool {
+	return !k.expiry.IsZero() && ExpiresSoon(k.expiry, k.now())
 }
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testJWT(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"system:serviceaccount:default:app","exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJSUzI1NiJ9." + payload + ".c2lnbmF0dXJl"
+}
+
+func TestKubernetesTokenSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	now := time.Now()
+	first := testJWT(now.Add(time.Hour))
+	if err := os.WriteFile(path, []byte(first+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	source := newKubernetesTokenSource(KubernetesConfig{TokenPath: path})
+	source.now = func() time.Time { return now }
+
+	got, err := source.getToken()
+	if err != nil || got != first {
+		t.Fatalf("getToken() = %q, %v, want trimmed token", got, err)
+	}
+
+	// Rotation replaces the file, which is picked up on the next call.
+	second := testJWT(now.Add(2 * time.Hour))
+	if err := os.WriteFile(path, []byte(second), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, now.Add(time.Minute), now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := source.getToken(); err != nil || got != second {
+		t.Errorf("getToken() after rotation = %q, %v, want rotated token", got, err)
+	}
+
+	// Expired tokens that have not been rotated are reported.
+	source.now = func() time.Time { return now.Add(3 * time.Hour) }
+	if _, err := source.getToken(); err == nil {
+		t.Errorf("getToken() with expired token succeeded")
+	}
+}
@@ -3,6 +3,7 @@ package auth
 
 import (
 	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/environment"
 	"github.com/SneaksAndData/esd-services-api-client-go/shared/httpclient"
 	"net/http"
 	"strings"
@@ -13,11 +14,30 @@ type Service struct {
 	httpClient *httpclient.Client
 	tokenURL   string
 	provider   string
+	env        string
+	chain      *tokenChain
+	cache      *tokenCache
+	cacheKey   string
 }
 
 // GetBoxerToken retrieves an authentication token from the configured provider.
 func (s *Service) GetBoxerToken() (string, error) {
-	targetURL := fmt.Sprintf("%s/token/%s", s.tokenURL, s.provider)
+	if s.cache != nil {
+		return s.cache.getOrRefresh(s.cacheKey, s.requestBoxerToken)
+	}
+	return s.requestBoxerToken()
+}
+
+func (s *Service) requestBoxerToken() (string, error) {
+	provider := s.provider
+	if s.chain != nil {
+		link, err := s.chain.resolve()
+		if err != nil {
+			return "", err
+		}
+		provider = link.provider
+	}
+	targetURL := httpclient.NewURL(s.tokenURL).Path("token", provider).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -25,26 +45,113 @@ func (s *Service) GetBoxerToken() (string, error) {
 	return string(response), nil
 }
 
+// Source returns the identity token source in use. For the chain provider this is the source that succeeded,
+// or an empty string before the first token was retrieved or when the Boxer token came from the cache.
+func (s *Service) Source() string {
+	if s.chain != nil {
+		return string(s.chain.source())
+	}
+	return s.provider
+}
+
+// Env returns the configured environment, so other services can be created for the same environment.
+func (s *Service) Env() string {
+	return s.env
+}
+
+// InvalidateBoxerToken discards the Boxer token cached on disk, so that the next GetBoxerToken call requests
+// a new one. Clients using the token call it when a request fails authorization, see httpclient.NewClientWithInvalidate.
+func (s *Service) InvalidateBoxerToken() {
+	if s.cache != nil {
+		s.cache.invalidate(s.cacheKey)
+	}
+}
+
+// identityClient creates the client requesting Boxer tokens with the identity tokens of getToken, served from the
+// on-disk cache when it is enabled. Identity tokens rejected by Boxer are discarded from the cache and, through
+// invalidate, from the source.
+func (s *Service) identityClient(key string, getToken func() (string, error), invalidate func()) *httpclient.Client {
+	if s.cache == nil {
+		return httpclient.NewClientWithInvalidate(getToken, invalidate)
+	}
+	return httpclient.NewClientWithInvalidate(s.cache.wrap(key, getToken), func() {
+		s.cache.invalidate(key)
+		if invalidate != nil {
+			invalidate()
+		}
+	})
+}
+
 // Config represents the configuration inputs for creating a new auth service.
 type Config struct {
-	TokenURL string // tokenURL is the URL used to retrieve the Boxer internal token e.g. http://boxer.test.sneaksanddata.com.
-	Env      string
-	Provider string
+	TokenURL   string // tokenURL is the URL used to retrieve the Boxer internal token e.g. http://boxer.test.sneaksanddata.com.
+	Env        string // Environment whose Boxer token URL is used when TokenURL is empty, see the environment package
+	Provider   string
+	Azure      AzureConfig      // Azure AD credential settings, used by the azuread provider
+	Kubernetes KubernetesConfig // Service account token settings, used by the k8s-* providers
+	OIDC       OIDCConfig       // Token endpoint settings, used by the oidc-* and exchange-* providers
+	Static     StaticConfig     // Fixed token settings for local development, used by the static-* providers
+	Chain      ChainConfig      // Sources tried in order by the chain provider
+	Cache      CacheConfig      // On-disk cache of Boxer tokens and of identity tokens acquired over the network
 }
 
 // New initializes a new Service instance using the provided Config.
 // It sets up the Service with an appropriate HTTP client based on the specified provider.
 func New(c Config) (*Service, error) {
+	tokenURL, err := environment.Resolve(c.TokenURL, c.Env, func(p environment.Profile) string { return p.BoxerTokenURL })
+	if err != nil {
+		return nil, err
+	}
+	tokenURL, err = httpclient.NormalizeBaseURL(tokenURL)
+	if err != nil {
+		return nil, err
+	}
 	s := &Service{httpClient: nil}
-	s.tokenURL = c.TokenURL
+	s.tokenURL = tokenURL
 	s.provider = c.Provider
+	s.env = c.Env
+	c.TokenURL = tokenURL
+	if c.Cache.Enabled {
+		if s.cache, err = newTokenCache(c.Cache); err != nil {
+			return nil, err
+		}
+		s.cacheKey = cacheKey("boxer", c)
+	}
 
 	switch {
 	case c.Provider == "azuread":
-		s.httpClient = httpclient.NewClient(getAzureDefaultToken)
+		source, err := newAzureTokenSource(c.Azure)
+		if err != nil {
+			return nil, err
+		}
+		s.httpClient = s.identityClient(cacheKey("identity", c), source.getToken, nil)
 	case strings.HasPrefix(c.Provider, "k8s"):
 		s.provider = strings.TrimPrefix(c.Provider, "k8s-")
-		s.httpClient = httpclient.NewClient(getKubernetesToken)
+		s.httpClient = httpclient.NewClient(newKubernetesTokenSource(c.Kubernetes).getToken)
+	case strings.HasPrefix(c.Provider, "oidc-"):
+		s.provider = strings.TrimPrefix(c.Provider, "oidc-")
+		source, err := newClientCredentialsSource(c.OIDC)
+		if err != nil {
+			return nil, err
+		}
+		s.httpClient = s.identityClient(cacheKey("identity", c), source.getToken, source.invalidate)
+	case strings.HasPrefix(c.Provider, "exchange-"):
+		s.provider = strings.TrimPrefix(c.Provider, "exchange-")
+		source, err := newTokenExchangeSource(c.OIDC)
+		if err != nil {
+			return nil, err
+		}
+		s.httpClient = s.identityClient(cacheKey("identity", c), source.getToken, source.invalidate)
+	case strings.HasPrefix(c.Provider, "static-"):
+		s.provider = strings.TrimPrefix(c.Provider, "static-")
+		s.httpClient = httpclient.NewClient(newStaticTokenSource(c.Static))
+	case c.Provider == "chain":
+		chain, err := newTokenChain(c)
+		if err != nil {
+			return nil, err
+		}
+		s.chain = chain
+		s.httpClient = httpclient.NewClient(chain.getToken)
 	default:
 		return nil, fmt.Errorf("unsupported token provider: %s", c.Provider)
 	}
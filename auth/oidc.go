@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	grantTypeClientCredentials = "client_credentials"
+	grantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// defaultSubjectTokenType is used for token exchange when no subject token type is configured.
+	defaultSubjectTokenType = "urn:ietf:params:oauth:token-type:jwt"
+	// defaultStaticTokenEnv is read by static-* providers when neither a token nor a variable is configured.
+	defaultStaticTokenEnv = "ESD_AUTH_TOKEN"
+)
+
+// OIDCConfig configures the oidc-* (client credentials) and exchange-* (RFC 8693 token exchange) providers.
+type OIDCConfig struct {
+	TokenEndpoint string   // OAuth 2.0 token endpoint of the identity provider
+	ClientID      string   // Client identifier, required for client credentials
+	ClientSecret  string   // Client secret, sent in the request body
+	Scopes        []string // Scopes to request
+	Audience      string   // Audience of the requested token, if the identity provider requires it
+
+	// SubjectTokenPath is the file holding the token to exchange, defaults to the Kubernetes service account token.
+	SubjectTokenPath string
+	// SubjectTokenType is the RFC 8693 type of the subject token, defaults to urn:ietf:params:oauth:token-type:jwt.
+	SubjectTokenType string
+	// RequestedTokenType is the RFC 8693 type of the token to issue, left to the identity provider when empty.
+	RequestedTokenType string
+}
+
+// StaticConfig configures the static-* provider, meant for local development.
+type StaticConfig struct {
+	Token  string // Token to use as is
+	EnvVar string // Environment variable holding the token when Token is empty, defaults to ESD_AUTH_TOKEN
+}
+
+// tokenEndpointSource requests tokens from an OAuth 2.0 token endpoint and caches them until they expire.
+type tokenEndpointSource struct {
+	endpoint   string
+	form       func() (url.Values, error)
+	httpClient *http.Client
+	mu         sync.Mutex
+	token      string
+	expiry     time.Time
+}
+
+type tokenEndpointResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func newClientCredentialsSource(c OIDCConfig) (*tokenEndpointSource, error) {
+	if c.TokenEndpoint == "" || c.ClientID == "" {
+		return nil, fmt.Errorf("client credentials require a token endpoint and a client id")
+	}
+	return newTokenEndpointSource(c.TokenEndpoint, func() (url.Values, error) {
+		return oidcForm(c, grantTypeClientCredentials), nil
+	}), nil
+}
+
+func newTokenExchangeSource(c OIDCConfig) (*tokenEndpointSource, error) {
+	if c.TokenEndpoint == "" {
+		return nil, fmt.Errorf("token exchange requires a token endpoint")
+	}
+	subject := newKubernetesTokenSource(KubernetesConfig{TokenPath: c.SubjectTokenPath})
+	subjectTokenType := c.SubjectTokenType
+	if subjectTokenType == "" {
+		subjectTokenType = defaultSubjectTokenType
+	}
+	return newTokenEndpointSource(c.TokenEndpoint, func() (url.Values, error) {
+		subjectToken, err := subject.getToken()
+		if err != nil {
+			return nil, fmt.Errorf("error reading subject token: %w", err)
+		}
+		form := oidcForm(c, grantTypeTokenExchange)
+		form.Set("subject_token", subjectToken)
+		form.Set("subject_token_type", subjectTokenType)
+		if c.RequestedTokenType != "" {
+			form.Set("requested_token_type", c.RequestedTokenType)
+		}
+		return form, nil
+	}), nil
+}
+
+func newTokenEndpointSource(endpoint string, form func() (url.Values, error)) *tokenEndpointSource {
+	return &tokenEndpointSource{
+		endpoint:   endpoint,
+		form:       form,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// oidcForm builds the parameters shared by all grants.
+func oidcForm(c OIDCConfig, grantType string) url.Values {
+	form := url.Values{}
+	form.Set("grant_type", grantType)
+	if c.ClientID != "" {
+		form.Set("client_id", c.ClientID)
+	}
+	if c.ClientSecret != "" {
+		form.Set("client_secret", c.ClientSecret)
+	}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	if c.Audience != "" {
+		form.Set("audience", c.Audience)
+	}
+	return form
+}
+
+func (t *tokenEndpointSource) getToken() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && !ExpiresSoon(t.expiry, time.Now()) {
+		return t.token, nil
+	}
+
+	form, err := t.form()
+	if err != nil {
+		return "", err
+	}
+	response, err := t.httpClient.PostForm(t.endpoint, form)
+	if err != nil {
+		return "", fmt.Errorf("error making request to %s: %w", t.endpoint, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed with status code: %d - %s", response.StatusCode, string(body))
+	}
+
+	var token tokenEndpointResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token endpoint returned no access token")
+	}
+	t.token = token.AccessToken
+	t.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return t.token, nil
+}
+
+// invalidate discards the cached token, i.e. after Boxer rejected it, so that the next call requests a new one.
+func (t *tokenEndpointSource) invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+	t.expiry = time.Time{}
+}
+
+// newStaticTokenSource returns the configured token, or reads it from the environment on every call.
+func newStaticTokenSource(c StaticConfig) func() (string, error) {
+	if c.Token != "" {
+		return func() (string, error) { return c.Token, nil }
+	}
+	envVar := staticTokenEnv(c)
+	return func() (string, error) {
+		token := strings.TrimSpace(os.Getenv(envVar))
+		if token == "" {
+			return "", fmt.Errorf("environment variable %s is not set", envVar)
+		}
+		return token, nil
+	}
+}
+
+// staticTokenEnv returns the environment variable holding the static token.
+func staticTokenEnv(c StaticConfig) string {
+	if c.EnvVar == "" {
+		return defaultStaticTokenEnv
+	}
+	return c.EnvVar
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTokenEndpoint serves tokens for client credentials and token exchange grants.
+func newTokenEndpoint(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		switch r.PostForm.Get("grant_type") {
+		case grantTypeClientCredentials:
+			if r.PostForm.Get("client_id") != "client" || r.PostForm.Get("client_secret") != "secret" {
+				http.Error(w, `{"error": "invalid_client"}`, http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token": "cc-%s", "token_type": "Bearer", "expires_in": 3600}`, r.PostForm.Get("scope"))
+		case grantTypeTokenExchange:
+			fmt.Fprintf(w, `{"access_token": "exchanged-%s", "token_type": "Bearer", "expires_in": 3600}`, r.PostForm.Get("subject_token"))
+		default:
+			http.Error(w, `{"error": "unsupported_grant_type"}`, http.StatusBadRequest)
+		}
+	}))
+}
+
+// newBoxer serves Boxer tokens, echoing the identity token it was called with.
+func newBoxer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.URL.Path, r.Header.Get("Authorization"))
+	}))
+}
+
+func TestProviders(t *testing.T) {
+	tokenEndpoint := newTokenEndpoint(t)
+	defer tokenEndpoint.Close()
+	boxer := newBoxer()
+	defer boxer.Close()
+
+	subjectPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(subjectPath, []byte("subject\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{
+			name: "Client credentials",
+			config: Config{Provider: "oidc-keycloak", OIDC: OIDCConfig{
+				TokenEndpoint: tokenEndpoint.URL, ClientID: "client", ClientSecret: "secret", Scopes: []string{"boxer"},
+			}},
+			want: "/token/keycloak Bearer cc-boxer",
+		},
+		{
+			name: "Token exchange",
+			config: Config{Provider: "exchange-keycloak", OIDC: OIDCConfig{
+				TokenEndpoint: tokenEndpoint.URL, SubjectTokenPath: subjectPath,
+			}},
+			want: "/token/keycloak Bearer exchanged-subject",
+		},
+		{
+			name:   "Static token",
+			config: Config{Provider: "static-local", Static: StaticConfig{Token: "local"}},
+			want:   "/token/local Bearer local",
+		},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.TokenURL = boxer.URL
+			service, err := New(tt.config)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			got, err := service.GetBoxerToken()
+			if err != nil {
+				t.Fatalf("GetBoxerToken() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetBoxerToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCredentialsCaching(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"access_token": "token", "expires_in": 3600}`)
+	}))
+	defer server.Close()
+
+	source, err := newClientCredentialsSource(OIDCConfig{TokenEndpoint: server.URL, ClientID: "client"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := source.getToken(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("token requests = %d, want 1", requests)
+	}
+
+	source.expiry = time.Now()
+	if _, err := source.getToken(); err != nil || requests != 2 {
+		t.Errorf("expired token was not refreshed: requests = %d, err = %v", requests, err)
+	}
+}
+
+func TestClientCredentialsInvalidatedOnAuthFailure(t *testing.T) {
+	var issued int
+	tokenEndpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued++
+		fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": 3600}`, issued)
+	}))
+	defer tokenEndpoint.Close()
+	// Boxer rejects the first identity token, i.e. because it was revoked before it expired.
+	boxer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	}))
+	defer boxer.Close()
+
+	service, err := New(Config{
+		TokenURL: boxer.URL,
+		Provider: "oidc-partner",
+		OIDC:     OIDCConfig{TokenEndpoint: tokenEndpoint.URL, ClientID: "client"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := service.GetBoxerToken()
+	if err != nil || token != "Bearer token-2" || issued != 2 {
+		t.Errorf("GetBoxerToken() = %q, %v after %d token requests, want a fresh identity token", token, err, issued)
+	}
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"time"
+)
+
+// VerifyOptions selects the keys a token signature is checked against. Exactly one source must be set.
+type VerifyOptions struct {
+	KeyFile string // PEM public key, PEM certificate or JWKS file
+	JWKSURL string // URL of a JWKS document, i.e. the jwks_uri of the issuer
+}
+
+// Verify decodes a JWT like Inspect and checks its signature. Expiry is not enforced, see TokenInfo.IsExpired.
+func Verify(token string, options VerifyOptions) (*TokenInfo, error) {
+	info, err := Inspect(token)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := loadVerificationKeys(options)
+	if err != nil {
+		return nil, err
+	}
+
+	header, payload, signature, _ := splitJWT(token)
+	signed := []byte(header + "." + payload)
+	var errs []error
+	for _, key := range keys {
+		if key.id != "" && info.Header.KeyID != "" && key.id != info.Header.KeyID {
+			continue
+		}
+		if err := verifySignature(info.Header.Algorithm, key.key, signed, signature); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		info.Verified = true
+		return info, nil
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no key found for key id %q", info.Header.KeyID)
+	}
+	return nil, fmt.Errorf("invalid token signature: %v", errs[0])
+}
+
+type verificationKey struct {
+	id  string
+	key crypto.PublicKey
+}
+
+func loadVerificationKeys(options VerifyOptions) ([]verificationKey, error) {
+	switch {
+	case options.KeyFile != "" && options.JWKSURL != "":
+		return nil, fmt.Errorf("only one of KeyFile and JWKSURL can be set")
+	case options.KeyFile != "":
+		content, err := os.ReadFile(options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading key file: %w", err)
+		}
+		if block, _ := pem.Decode(content); block != nil {
+			key, err := parsePEMPublicKey(block)
+			if err != nil {
+				return nil, err
+			}
+			return []verificationKey{{key: key}}, nil
+		}
+		return parseJWKS(content)
+	case options.JWKSURL != "":
+		client := &http.Client{Timeout: 30 * time.Second}
+		response, err := client.Get(options.JWKSURL)
+		if err != nil {
+			return nil, fmt.Errorf("error making request to %s: %w", options.JWKSURL, err)
+		}
+		defer response.Body.Close()
+		content, err := io.ReadAll(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("JWKS request failed with status code: %d - %s", response.StatusCode, string(content))
+		}
+		return parseJWKS(content)
+	default:
+		return nil, fmt.Errorf("no verification key configured")
+	}
+}
+
+func parsePEMPublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// parseJWKS reads the signing keys of a JWKS document, skipping keys of unsupported types.
+func parseJWKS(content []byte) ([]verificationKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("error unmarshaling JWKS: %w", err)
+	}
+
+	var keys []verificationKey
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", jwk.KeyID, err)
+		}
+		if key != nil {
+			keys = append(keys, verificationKey{id: jwk.KeyID, key: key})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no supported signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(content), nil
+}
+
+var signatureHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// verifySignature checks a JWS signature for the asymmetric algorithms of RFC 7518 and EdDSA.
+func verifySignature(algorithm string, key crypto.PublicKey, signed []byte, signature []byte) error {
+	if algorithm == "EdDSA" {
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(edKey, signed, signature) {
+			return fmt.Errorf("EdDSA signature does not match")
+		}
+		return nil
+	}
+
+	hash, ok := signatureHashes[algorithm]
+	if !ok {
+		return fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+	hasher := hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch algorithm[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(key, hash, digest, signature)
+		case "PS":
+			return rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case *ecdsa.PublicKey:
+		if algorithm[:2] == "ES" {
+			size := (key.Curve.Params().BitSize + 7) / 8
+			if len(signature) != 2*size {
+				return fmt.Errorf("invalid %s signature length", algorithm)
+			}
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			if !ecdsa.Verify(key, digest, r, s) {
+				return fmt.Errorf("%s signature does not match", algorithm)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("key of type %T cannot verify %s signatures", key, algorithm)
+}
@@ -0,0 +1,23 @@
+package claim
+
+import "context"
+
+// Client is implemented by Service, so code depending on Boxer claims can be tested with a mock, see the mocks package.
+type Client interface {
+	GetClaim(user string, provider string) (string, error)
+	GetUserClaims(user string, provider string) (*UserClaims, error)
+	AddClaim(user string, provider string, claims []string) (string, error)
+	AddClaims(user string, provider string, claims []Claim) (*UserClaims, error)
+	RemoveClaim(user string, provider string, claims []string) (string, error)
+	RemoveClaims(user string, provider string, claims []Claim) (*UserClaims, error)
+	AddUser(user string, provider string) (string, error)
+	RemoveUser(user string, provider string) (string, error)
+	ProvisionUsers(ctx context.Context, provider string, users []UserProvisioning, roles []Role) []ProvisionResult
+	Reconcile(ctx context.Context, desired DesiredState, opts ReconcileOptions) (*Plan, error)
+	Plan(ctx context.Context, desired DesiredState) (*Plan, error)
+	Apply(ctx context.Context, plan Plan) error
+	Export(ctx context.Context, provider string, users []string) (*Snapshot, error)
+	Import(ctx context.Context, snapshot Snapshot, opts ReconcileOptions) (*Plan, error)
+}
+
+var _ Client = (*Service)(nil)
@@ -0,0 +1,81 @@
+package claim
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ClaimCheck records how a single claim was matched against a request.
+type ClaimCheck struct {
+	Claim         Claim
+	PathMatched   bool
+	MethodMatched bool
+}
+
+// Explanation details how a decision was reached.
+type Explanation struct {
+	Allowed bool
+	Path    string
+	Method  string
+	// Matched is the first claim granting access, nil when access is denied.
+	Matched *Claim
+	// Checks lists every claim evaluated, in order.
+	Checks []ClaimCheck
+}
+
+// String formats the explanation for debugging output.
+func (e Explanation) String() string {
+	var b strings.Builder
+	if e.Allowed {
+		fmt.Fprintf(&b, "%s %s allowed by %s", e.Method, e.Path, e.Matched)
+	} else {
+		fmt.Fprintf(&b, "%s %s denied: no claim matches", e.Method, e.Path)
+	}
+	for _, check := range e.Checks {
+		fmt.Fprintf(&b, "\n  %s path=%t method=%t", check.Claim, check.PathMatched, check.MethodMatched)
+	}
+	return b.String()
+}
+
+// Evaluate reports whether the claims allow calling method on path, using Boxer's semantics:
+// access is granted when any claim's path regex matches the whole path and its pattern regex
+// matches the whole HTTP method.
+func Evaluate(claims []Claim, path string, method string) (bool, error) {
+	explanation, err := Explain(claims, path, method)
+	if err != nil {
+		return false, err
+	}
+	return explanation.Allowed, nil
+}
+
+// Explain evaluates the claims like Evaluate and reports which claims matched.
+func Explain(claims []Claim, path string, method string) (Explanation, error) {
+	method = strings.ToUpper(method)
+	explanation := Explanation{Path: path, Method: method, Checks: make([]ClaimCheck, 0, len(claims))}
+	for i, c := range claims {
+		pathMatched, err := matchWhole(c.Path, path)
+		if err != nil {
+			return Explanation{}, fmt.Errorf("invalid path regex in claim %s: %w", c, err)
+		}
+		methodMatched, err := matchWhole(c.Pattern, method)
+		if err != nil {
+			return Explanation{}, fmt.Errorf("invalid pattern regex in claim %s: %w", c, err)
+		}
+		explanation.Checks = append(explanation.Checks, ClaimCheck{Claim: c, PathMatched: pathMatched, MethodMatched: methodMatched})
+		if pathMatched && methodMatched && explanation.Matched == nil {
+			explanation.Allowed = true
+			explanation.Matched = &claims[i]
+		}
+	}
+	return explanation, nil
+}
+
+// matchWhole reports whether the regex matches the entire value.
+func matchWhole(expression string, value string) (bool, error) {
+	re, err := regexp.Compile("^(?:" + expression + ")$")
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(value), nil
+}
@@ -3,9 +3,9 @@ package claim
 
 import (
 	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/environment"
 	"github.com/SneaksAndData/esd-services-api-client-go/shared/httpclient"
 	"net/http"
-	"strings"
 )
 
 // Service represents a client for the claim management service.
@@ -23,7 +23,7 @@ type claimPayload struct {
 
 // GetClaim retrieves the claims for a given user and provider.
 func (s Service) GetClaim(user string, provider string) (string, error) {
-	targetURL := fmt.Sprintf("%s/claim/%s/%s", s.claimURL, provider, user)
+	targetURL := httpclient.NewURL(s.claimURL).Path("claim", provider, user).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -31,33 +31,92 @@ func (s Service) GetClaim(user string, provider string) (string, error) {
 	return string(response), nil
 }
 
+// GetUserClaims retrieves the claims for a given user and provider as a typed result.
+func (s Service) GetUserClaims(user string, provider string) (*UserClaims, error) {
+	response, err := s.GetClaim(user, provider)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUserClaims([]byte(response))
+}
+
 // AddClaim adds claims for a user under a specific provider.
+// Claims are given in the "path:pattern" form; prefer AddClaims for typed input and output.
 func (s Service) AddClaim(user string, provider string, claims []string) (string, error) {
-	targetURL := fmt.Sprintf("%s/claim/%s/%s", s.claimURL, provider, user)
-	response, err := s.httpClient.MakeRequest(http.MethodPatch, targetURL, preparePayload(claims, "Insert"))
+	payload, err := preparePayload(claims, "Insert")
 	if err != nil {
-		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
+		return "", err
 	}
-	return string(response), nil
+	response, err := s.patchClaims(user, provider, payload)
+	return string(response), err
+}
+
+// AddClaims adds claims for a user under a specific provider and returns the resulting user claims.
+// Claims are validated like ParseClaims does before anything is sent.
+func (s Service) AddClaims(user string, provider string, claims []Claim) (*UserClaims, error) {
+	if err := validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return s.patchUserClaims(user, provider, newPayload(claims, "Insert"))
 }
 
 // RemoveClaim removes claims for a user under a specific provider.
+// Claims are given in the "path:pattern" form; prefer RemoveClaims for typed input and output.
 func (s Service) RemoveClaim(user string, provider string, claims []string) (string, error) {
-	targetURL := fmt.Sprintf("%s/claim/%s/%s", s.claimURL, provider, user)
-	response, err := s.httpClient.MakeRequest(http.MethodPatch, targetURL, preparePayload(claims, "Delete"))
+	payload, err := preparePayload(claims, "Delete")
 	if err != nil {
-		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
+		return "", err
 	}
-	return string(response), nil
+	response, err := s.patchClaims(user, provider, payload)
+	return string(response), err
 }
 
-// preparePayload prepares the payload for claim operations.
-func preparePayload(claims []string, operation string) claimPayload {
-	claimsMap := make(map[string]string)
-	for _, s := range claims {
-		c := strings.Split(s, ":")
-		claimsMap[c[0]] = c[1]
+// RemoveClaims removes claims for a user under a specific provider and returns the resulting user claims.
+// Claims are validated like ParseClaims does before anything is sent.
+func (s Service) RemoveClaims(user string, provider string, claims []Claim) (*UserClaims, error) {
+	if err := validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return s.patchUserClaims(user, provider, newPayload(claims, "Delete"))
+}
+
+// patchClaims sends a claim operation for a user under a specific provider.
+func (s Service) patchClaims(user string, provider string, payload claimPayload) ([]byte, error) {
+	targetURL := httpclient.NewURL(s.claimURL).Path("claim", provider, user).String()
+	response, err := s.httpClient.MakeRequest(http.MethodPatch, targetURL, payload)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to %s: %w", targetURL, err)
+	}
+	return response, nil
+}
+
+// patchUserClaims sends a claim operation and decodes the resulting user claims.
+// Boxer may answer with an empty body, in which case the claims are fetched separately.
+func (s Service) patchUserClaims(user string, provider string, payload claimPayload) (*UserClaims, error) {
+	response, err := s.patchClaims(user, provider, payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) == 0 {
+		return s.GetUserClaims(user, provider)
+	}
+	return decodeUserClaims(response)
+}
+
+// preparePayload parses claims in the "path:pattern" form and prepares the payload for claim operations.
+func preparePayload(claims []string, operation string) (claimPayload, error) {
+	parsed, err := ParseClaims(claims)
+	if err != nil {
+		return claimPayload{}, err
+	}
+	return newPayload(parsed, operation), nil
+}
 
+// newPayload prepares the payload for claim operations from typed claims.
+func newPayload(claims []Claim, operation string) claimPayload {
+	claimsMap := make(map[string]string)
+	for _, c := range claims {
+		claimsMap[c.Path] = c.Pattern
 	}
 	return claimPayload{
 		Operation: operation,
@@ -67,7 +126,7 @@ func preparePayload(claims []string, operation string) claimPayload {
 
 // AddUser creates a new user under a specific provider.
 func (s Service) AddUser(user string, provider string) (string, error) {
-	targetURL := fmt.Sprintf("%s/claim/%s/%s", s.claimURL, provider, user)
+	targetURL := httpclient.NewURL(s.claimURL).Path("claim", provider, user).String()
 	response, err := s.httpClient.MakeRequest(http.MethodPost, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -77,7 +136,7 @@ func (s Service) AddUser(user string, provider string) (string, error) {
 
 // RemoveUser deletes a user under a specific provider.
 func (s Service) RemoveUser(user string, provider string) (string, error) {
-	targetURL := fmt.Sprintf("%s/claim/%s/%s", s.claimURL, provider, user)
+	targetURL := httpclient.NewURL(s.claimURL).Path("claim", provider, user).String()
 	response, err := s.httpClient.MakeRequest(http.MethodDelete, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -88,15 +147,24 @@ func (s Service) RemoveUser(user string, provider string) (string, error) {
 // Config holds the configuration needed to initialize a new Service instance.
 type Config struct {
 	ClaimURL     string
+	Env          string // Environment whose Boxer claim URL is used when ClaimURL is empty, see the environment package
 	GetTokenFunc func() (string, error)
-	HTTPClient   *httpclient.Client
+	HTTPClient   *httpclient.Client // Shared HTTP client, GetTokenFunc is ignored when it is set
 }
 
 // New initializes a new instance of the Service using the provided Config.
 func New(c Config) (*Service, error) {
+	claimURL, err := environment.Resolve(c.ClaimURL, c.Env, func(p environment.Profile) string { return p.BoxerClaimURL })
+	if err != nil {
+		return nil, err
+	}
+	claimURL, err = httpclient.NormalizeBaseURL(claimURL)
+	if err != nil {
+		return nil, err
+	}
 	s := &Service{
-		httpClient: httpclient.NewClient(c.GetTokenFunc),
-		claimURL:   c.ClaimURL,
+		httpClient: httpclient.ClientOrNew(c.HTTPClient, c.GetTokenFunc),
+		claimURL:   claimURL,
 	}
 	return s, nil
 }
@@ -12,6 +12,7 @@ func TestPreparePayload(t *testing.T) {
 		claims    []string
 		operation string
 		want      claimPayload
+		wantErr   bool
 	}{
 		{
 			name:      "Single claim",
@@ -31,15 +32,193 @@ func TestPreparePayload(t *testing.T) {
 				Claims:    map[string]string{"claim1": ".*", "claim2": ".*"},
 			},
 		},
+		{
+			name:      "Separator in pattern",
+			claims:    []string{"claim1:https?://.*"},
+			operation: "insert",
+			want: claimPayload{
+				Operation: "insert",
+				Claims:    map[string]string{"claim1": "https?://.*"},
+			},
+		},
+		{
+			name:      "Quoted and escaped paths",
+			claims:    []string{`"claim:1":.*`, `claim\:2:GET`},
+			operation: "insert",
+			want: claimPayload{
+				Operation: "insert",
+				Claims:    map[string]string{"claim:1": ".*", "claim:2": "GET"},
+			},
+		},
+		{
+			name:      "Regex escapes in path",
+			claims:    []string{`claim\.1:.*`, `claim\\:GET`},
+			operation: "insert",
+			want: claimPayload{
+				Operation: "insert",
+				Claims:    map[string]string{`claim\.1`: ".*", `claim\`: "GET"},
+			},
+		},
+		{
+			name:      "Missing separator",
+			claims:    []string{"claim1"},
+			operation: "insert",
+			wantErr:   true,
+		},
+		{
+			name:      "Empty path",
+			claims:    []string{":.*"},
+			operation: "insert",
+			wantErr:   true,
+		},
+		{
+			name:      "Invalid pattern",
+			claims:    []string{"claim1:(.*"},
+			operation: "insert",
+			wantErr:   true,
+		},
+		{
+			name:      "Duplicate paths",
+			claims:    []string{"claim1:.*", "claim1:GET"},
+			operation: "insert",
+			wantErr:   true,
+		},
 	}
 
 	// Run test cases
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := preparePayload(tt.claims, tt.operation)
-			if !reflect.DeepEqual(got, tt.want) {
+			got, err := preparePayload(tt.claims, tt.operation)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("preparePayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("preparePayload() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestDecodeUserClaims(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name     string
+		response string
+		want     *UserClaims
+	}{
+		{
+			name:     "List of claims",
+			response: `{"userId": "user@example.com", "identityProvider": "azuread", "claims": [{"crystal.*": ".*"}, {"beast.*": "GET"}]}`,
+			want: &UserClaims{
+				UserID:           "user@example.com",
+				IdentityProvider: "azuread",
+				Claims:           Claims{{Path: "crystal.*", Pattern: ".*"}, {Path: "beast.*", Pattern: "GET"}},
+			},
+		},
+		{
+			name:     "Map of claims",
+			response: `{"userId": "user@example.com", "identityProvider": "azuread", "claims": {"crystal.*": ".*"}}`,
+			want: &UserClaims{
+				UserID:           "user@example.com",
+				IdentityProvider: "azuread",
+				Claims:           Claims{{Path: "crystal.*", Pattern: ".*"}},
+			},
+		},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeUserClaims([]byte(tt.response))
+			if err != nil {
+				t.Fatalf("decodeUserClaims() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeUserClaims() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	claims := []Claim{
+		{Path: "crystal.example.com/algorithm/.*", Pattern: "GET|POST"},
+		{Path: "beast.example.com/job/.*", Pattern: "GET"},
+	}
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		path   string
+		method string
+		want   bool
+	}{
+		{name: "Matching claim", path: "crystal.example.com/algorithm/v1.2/run/forecast", method: "post", want: true},
+		{name: "Method not granted", path: "beast.example.com/job/submit", method: "POST", want: false},
+		{name: "Partial path match", path: "evil.com/crystal.example.com/algorithm/x", method: "GET", want: false},
+		{name: "No matching path", path: "boxer.example.com/claim", method: "GET", want: false},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Evaluate(claims, tt.path, tt.method)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if got != tt.want {
+				explanation, _ := Explain(claims, tt.path, tt.method)
+				t.Errorf("Evaluate() = %v, want %v\n%s", got, tt.want, explanation)
+			}
+		})
+	}
+}
+
+func TestValidateClaims(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name    string
+		claims  []Claim
+		wantErr bool
+	}{
+		{name: "Valid claims", claims: []Claim{{Path: `claim\.1`, Pattern: ".*"}, {Path: "claim:2", Pattern: "GET"}}},
+		{name: "Empty path", claims: []Claim{{Path: "", Pattern: ".*"}}, wantErr: true},
+		{name: "Invalid pattern", claims: []Claim{{Path: "claim1", Pattern: "(.*"}}, wantErr: true},
+		{name: "Duplicate paths", claims: []Claim{{Path: "claim1", Pattern: ".*"}, {Path: "claim1", Pattern: "GET"}}, wantErr: true},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateClaims(tt.claims); (err != nil) != tt.wantErr {
+				t.Errorf("validateClaims() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClaimString(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name  string
+		claim Claim
+		want  string
+	}{
+		{name: "Plain path", claim: Claim{Path: "data/.*", Pattern: "GET"}, want: "data/.*:GET"},
+		{name: "Separator in path", claim: Claim{Path: "a:b", Pattern: ".*"}, want: `a\:b:.*`},
+		{name: "Quote in path", claim: Claim{Path: `a"b`, Pattern: ".*"}, want: `a\"b:.*`},
+		{name: "Backslash in path", claim: Claim{Path: `a\.b`, Pattern: "https?://.*"}, want: `a\\.b:https?://.*`},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claim.String(); got != tt.want {
+				t.Errorf("String() = %s, want %s", got, tt.want)
+			}
+			if parsed, err := ParseClaim(tt.claim.String()); err != nil || parsed != tt.claim {
+				t.Errorf("ParseClaim(String()) = %v, %v, want %v", parsed, err, tt.claim)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package claim
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Claim grants access to resources whose path matches the Path regex, for HTTP methods matching the Pattern regex.
+type Claim struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+}
+
+// String formats the claim as "path:pattern", the form accepted by AddClaim and RemoveClaim.
+// Separators, quotes and backslashes in the path are escaped so that the result parses back with ParseClaim.
+func (c Claim) String() string {
+	return pathEscaper.Replace(c.Path) + ":" + c.Pattern
+}
+
+var pathEscaper = strings.NewReplacer(`\`, `\\`, `:`, `\:`, `"`, `\"`)
+
+// Claims is a list of claims decoded from Boxer, which represents them as a list of single entry
+// objects, i.e. [{"path": "pattern"}], or as a single object mapping paths to patterns.
+type Claims []Claim
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	var entries []map[string]string
+	if err := json.Unmarshal(data, &entries); err == nil {
+		*c = Claims{}
+		for _, entry := range entries {
+			*c = append(*c, fromMap(entry)...)
+		}
+		return nil
+	}
+
+	var entry map[string]string
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("unexpected claims format: %s", string(data))
+	}
+	*c = fromMap(entry)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, producing the list form used by Boxer.
+func (c Claims) MarshalJSON() ([]byte, error) {
+	entries := make([]map[string]string, 0, len(c))
+	for _, claim := range c {
+		entries = append(entries, map[string]string{claim.Path: claim.Pattern})
+	}
+	return json.Marshal(entries)
+}
+
+// fromMap converts a path to pattern map into claims sorted by path.
+func fromMap(entry map[string]string) Claims {
+	claims := make(Claims, 0, len(entry))
+	for path, pattern := range entry {
+		claims = append(claims, Claim{Path: path, Pattern: pattern})
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].Path < claims[j].Path })
+	return claims
+}
+
+// UserClaims holds the claims Boxer grants to a user of an identity provider.
+type UserClaims struct {
+	UserID           string `json:"userId"`
+	IdentityProvider string `json:"identityProvider"`
+	Claims           Claims `json:"claims"`
+}
+
+// decodeUserClaims parses a Boxer user claims response.
+func decodeUserClaims(response []byte) (*UserClaims, error) {
+	var userClaims UserClaims
+	if err := json.Unmarshal(response, &userClaims); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return &userClaims, nil
+}
@@ -0,0 +1,117 @@
+package claim
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const claimSeparator = ':'
+
+// ParseClaim parses a claim in the "path:pattern" form.
+//
+// The path ends at the first separator that is neither escaped with a backslash nor inside a double-quoted
+// path, so paths containing ':' can be written as `"a:b":.*` or `a\:b:.*`. Only `\:`, `\"` and `\\` are escapes,
+// other backslashes are kept so that regex escapes such as `a\.b` reach Boxer unchanged. Everything after the separator is
+// the pattern, taken verbatim, so patterns such as `https?://.*` are kept intact. The path must not be empty
+// and the pattern must be a valid regular expression.
+func ParseClaim(s string) (Claim, error) {
+	path, pattern, err := splitClaim(s)
+	if err != nil {
+		return Claim{}, fmt.Errorf("invalid claim %q: %w", s, err)
+	}
+	c := Claim{Path: path, Pattern: pattern}
+	if err := validateClaim(c); err != nil {
+		return Claim{}, fmt.Errorf("invalid claim %q: %w", s, err)
+	}
+	return c, nil
+}
+
+// validateClaim checks that the path is not empty and that the pattern is a valid regular expression.
+func validateClaim(c Claim) error {
+	if c.Path == "" {
+		return fmt.Errorf("path is empty")
+	}
+	if _, err := regexp.Compile(c.Pattern); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateClaims checks typed claims with the same rules as ParseClaims. All problems are reported together.
+func validateClaims(claims []Claim) error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, c := range claims {
+		if err := validateClaim(c); err != nil {
+			errs = append(errs, fmt.Errorf("invalid claim %s: %w", c, err))
+			continue
+		}
+		if seen[c.Path] {
+			errs = append(errs, fmt.Errorf("duplicate claim path %q", c.Path))
+			continue
+		}
+		seen[c.Path] = true
+	}
+	return errors.Join(errs...)
+}
+
+// ParseClaims parses every claim and rejects duplicate paths. All problems are reported together.
+func ParseClaims(claims []string) ([]Claim, error) {
+	var errs []error
+	parsed := make([]Claim, 0, len(claims))
+	seen := make(map[string]bool)
+	for _, s := range claims {
+		c, err := ParseClaim(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if seen[c.Path] {
+			errs = append(errs, fmt.Errorf("duplicate claim path %q", c.Path))
+			continue
+		}
+		seen[c.Path] = true
+		parsed = append(parsed, c)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return parsed, nil
+}
+
+// splitClaim splits a claim on the first unquoted and unescaped separator, unescaping the path.
+func splitClaim(s string) (string, string, error) {
+	var path strings.Builder
+	quoted := strings.HasPrefix(s, `"`)
+	start := 0
+	if quoted {
+		start = 1
+	}
+	for i := start; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\' && i+1 < len(s) && isEscapable(s[i+1]):
+			i++
+			path.WriteByte(s[i])
+		case ch == '"' && quoted:
+			if i+1 == len(s) || s[i+1] != claimSeparator {
+				return "", "", fmt.Errorf("quoted path must be followed by %q", claimSeparator)
+			}
+			return path.String(), s[i+2:], nil
+		case ch == claimSeparator && !quoted:
+			return path.String(), s[i+1:], nil
+		default:
+			path.WriteByte(ch)
+		}
+	}
+	if quoted {
+		return "", "", fmt.Errorf("unterminated quoted path")
+	}
+	return "", "", fmt.Errorf("missing %q separator between path and pattern", claimSeparator)
+}
+
+// isEscapable reports whether a backslash before ch is an escape rather than part of the path.
+func isEscapable(ch byte) bool {
+	return ch == claimSeparator || ch == '"' || ch == '\\'
+}
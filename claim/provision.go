@@ -0,0 +1,158 @@
+package claim
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// maxConcurrentProvisions bounds the number of users provisioned at the same time.
+const maxConcurrentProvisions = 8
+
+var placeholderPattern = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// Role is a named set of claims granted together. Paths and patterns may contain placeholders
+// such as {team}, filled in per user when the role is rendered.
+type Role struct {
+	Name   string  `json:"name"`
+	Claims []Claim `json:"claims"`
+}
+
+// Render fills in the placeholders of the role's claims. Values are quoted so that they match literally
+// inside the claim regexes. Placeholders without a value are reported as an error.
+func (r Role) Render(values map[string]string) ([]Claim, error) {
+	rendered := make([]Claim, 0, len(r.Claims))
+	for _, c := range r.Claims {
+		path, err := fillPlaceholders(c.Path, values)
+		if err != nil {
+			return nil, fmt.Errorf("role %s: %w", r.Name, err)
+		}
+		pattern, err := fillPlaceholders(c.Pattern, values)
+		if err != nil {
+			return nil, fmt.Errorf("role %s: %w", r.Name, err)
+		}
+		rendered = append(rendered, Claim{Path: path, Pattern: pattern})
+	}
+	return rendered, nil
+}
+
+func fillPlaceholders(s string, values map[string]string) (string, error) {
+	var missing string
+	filled := placeholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		value, ok := values[name]
+		if !ok {
+			missing = name
+			return placeholder
+		}
+		return regexp.QuoteMeta(value)
+	})
+	if missing != "" {
+		return "", fmt.Errorf("no value for placeholder {%s} in %q", missing, s)
+	}
+	return filled, nil
+}
+
+// UserProvisioning identifies a user to provision and the placeholder values used to render roles for them.
+type UserProvisioning struct {
+	User   string
+	Values map[string]string
+}
+
+// ProvisionResult holds the outcome of provisioning a single user.
+type ProvisionResult struct {
+	User   string
+	Claims []Claim
+	// Created is set when the user did not exist before provisioning.
+	Created bool
+	// RolledBack is set when a user created by this call was removed again after a failure.
+	RolledBack bool
+	Err        error
+}
+
+// ProvisionUsers creates users under a provider and grants them the claims of all roles, concurrently.
+// Users that already exist keep their claims and receive the role claims on top. When granting claims
+// fails for a user created by this call, the user is removed again so that no half-provisioned users remain.
+func (s Service) ProvisionUsers(ctx context.Context, provider string, users []UserProvisioning, roles []Role) []ProvisionResult {
+	results := make([]ProvisionResult, len(users))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentProvisions)
+	for i, user := range users {
+		results[i].User = user.User
+		wg.Add(1)
+		go func(user UserProvisioning, result *ProvisionResult) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return
+			}
+			s.provisionUser(ctx, provider, user, roles, result)
+		}(user, &results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+func (s Service) provisionUser(ctx context.Context, provider string, user UserProvisioning, roles []Role, result *ProvisionResult) {
+	claims, err := renderRoles(roles, user.Values)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	result.Claims = claims
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return
+	}
+
+	_, exists, err := s.currentClaims(user.User, provider)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	if !exists {
+		if _, err := s.AddUser(user.User, provider); err != nil {
+			result.Err = fmt.Errorf("error creating user %s: %w", user.User, err)
+			return
+		}
+		result.Created = true
+	}
+
+	if _, err := s.patchClaims(user.User, provider, newPayload(claims, "Insert")); err != nil {
+		result.Err = fmt.Errorf("error granting claims to %s: %w", user.User, err)
+		if result.Created {
+			if _, rollbackErr := s.RemoveUser(user.User, provider); rollbackErr != nil {
+				result.Err = fmt.Errorf("%w; rollback failed: %v", result.Err, rollbackErr)
+				return
+			}
+			result.RolledBack = true
+		}
+	}
+}
+
+// renderRoles renders all roles for a user and merges their claims, rejecting conflicting patterns for the same path.
+func renderRoles(roles []Role, values map[string]string) ([]Claim, error) {
+	var claims []Claim
+	patterns := make(map[string]string)
+	for _, role := range roles {
+		rendered, err := role.Render(values)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range rendered {
+			if pattern, ok := patterns[c.Path]; ok {
+				if pattern != c.Pattern {
+					return nil, fmt.Errorf("role %s grants %q with pattern %q, conflicting with %q", role.Name, c.Path, c.Pattern, pattern)
+				}
+				continue
+			}
+			patterns[c.Path] = c.Pattern
+			claims = append(claims, c)
+		}
+	}
+	return claims, nil
+}
@@ -0,0 +1,110 @@
+package claim
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRoleRender(t *testing.T) {
+	role := Role{Name: "reader", Claims: []Claim{{Path: "data/{team}/.*", Pattern: "{method}"}}}
+
+	// Define test cases
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    []Claim
+		wantErr bool
+	}{
+		{
+			name:   "Values are quoted",
+			values: map[string]string{"team": "a.b", "method": "GET"},
+			want:   []Claim{{Path: `data/a\.b/.*`, Pattern: "GET"}},
+		},
+		{
+			name:    "Missing value",
+			values:  map[string]string{"team": "a"},
+			wantErr: true,
+		},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := role.Render(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Render() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderRoles(t *testing.T) {
+	reader := Role{Name: "reader", Claims: []Claim{{Path: "data/{team}", Pattern: "GET"}}}
+	writer := Role{Name: "writer", Claims: []Claim{{Path: "data/{team}", Pattern: ".*"}}}
+	values := map[string]string{"team": "a"}
+
+	claims, err := renderRoles([]Role{reader, reader}, values)
+	if err != nil || !reflect.DeepEqual(claims, []Claim{{Path: "data/a", Pattern: "GET"}}) {
+		t.Errorf("renderRoles() = %v, %v, want a single merged claim", claims, err)
+	}
+	if _, err := renderRoles([]Role{reader, writer}, values); err == nil {
+		t.Errorf("renderRoles() accepted conflicting patterns")
+	}
+}
+
+func TestProvisionUsers(t *testing.T) {
+	service, boxer := newFakeService(t, map[string]map[string]string{
+		"azuread/existing": {"other": ".*"},
+	})
+	boxer.fail["PATCH azuread/failing"] = true
+
+	users := []UserProvisioning{
+		{User: "existing", Values: map[string]string{"team": "a"}},
+		{User: "failing", Values: map[string]string{"team": "a"}},
+		{User: "unrendered"},
+	}
+	for i := 0; i < 3*maxConcurrentProvisions; i++ {
+		users = append(users, UserProvisioning{User: fmt.Sprintf("user-%d", i), Values: map[string]string{"team": "a"}})
+	}
+	roles := []Role{{Name: "reader", Claims: []Claim{{Path: "data/{team}", Pattern: "GET"}}}}
+
+	results := service.ProvisionUsers(context.Background(), "azuread", users, roles)
+	if len(results) != len(users) {
+		t.Fatalf("ProvisionUsers() returned %d results, want %d", len(results), len(users))
+	}
+
+	existing, failing, unrendered := results[0], results[1], results[2]
+	if existing.Err != nil || existing.Created || !reflect.DeepEqual(boxer.users["azuread/existing"], map[string]string{"other": ".*", "data/a": "GET"}) {
+		t.Errorf("existing user = %+v, claims %v", existing, boxer.users["azuread/existing"])
+	}
+	if failing.Err == nil || !failing.Created || !failing.RolledBack {
+		t.Errorf("failing user = %+v, want rolled back", failing)
+	}
+	if _, ok := boxer.users["azuread/failing"]; ok {
+		t.Errorf("failing user was not removed")
+	}
+	if unrendered.Err == nil || unrendered.Created {
+		t.Errorf("unrendered user = %+v, want a render error before any request", unrendered)
+	}
+	for _, result := range results[3:] {
+		if result.Err != nil || !result.Created || !reflect.DeepEqual(boxer.users["azuread/"+result.User], map[string]string{"data/a": "GET"}) {
+			t.Errorf("user %s = %+v, claims %v", result.User, result, boxer.users["azuread/"+result.User])
+		}
+	}
+}
+
+func TestProvisionUsersCancelled(t *testing.T) {
+	service, boxer := newFakeService(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := service.ProvisionUsers(ctx, "azuread", []UserProvisioning{{User: "alice"}}, nil)
+	if results[0].Err == nil || len(boxer.requests) != 0 {
+		t.Errorf("ProvisionUsers() = %+v, requests %v, want cancellation before any request", results, boxer.requests)
+	}
+}
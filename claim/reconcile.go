@@ -0,0 +1,221 @@
+package claim
+
+import (
+	"context"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/shared/file"
+	"github.com/SneaksAndData/esd-services-api-client-go/shared/httpclient"
+	"sort"
+	"strings"
+)
+
+// DesiredState describes the users and claims that should exist in Boxer, grouped by identity provider.
+type DesiredState struct {
+	Providers map[string]ProviderState `json:"providers"`
+}
+
+// ProviderState describes the users of a single identity provider.
+type ProviderState struct {
+	// Users maps user identifiers to their claims in the "path:pattern" form. Claims not listed are removed.
+	Users map[string][]string `json:"users"`
+	// Absent lists users that must not exist. Boxer cannot list users, so removals have to be explicit.
+	Absent []string `json:"absent"`
+}
+
+// LoadDesiredState reads a desired state from a JSON or YAML file, picking the format from the file extension.
+func LoadDesiredState(path string) (*DesiredState, error) {
+	var desired DesiredState
+	if err := file.ReadStructured(path, &desired); err != nil {
+		return nil, fmt.Errorf("error loading desired state: %w", err)
+	}
+	return &desired, nil
+}
+
+// ActionKind is the kind of change a plan applies to Boxer.
+type ActionKind string
+
+const (
+	ActionAddUser      ActionKind = "AddUser"
+	ActionRemoveUser   ActionKind = "RemoveUser"
+	ActionInsertClaims ActionKind = "InsertClaims"
+	ActionDeleteClaims ActionKind = "DeleteClaims"
+)
+
+// Action is a single change of a reconciliation plan.
+type Action struct {
+	Kind     ActionKind
+	Provider string
+	User     string
+	Claims   []Claim
+}
+
+// String formats the action for dry-run output.
+func (a Action) String() string {
+	switch a.Kind {
+	case ActionAddUser:
+		return fmt.Sprintf("+ user %s/%s", a.Provider, a.User)
+	case ActionRemoveUser:
+		return fmt.Sprintf("- user %s/%s", a.Provider, a.User)
+	}
+	sign := "+"
+	if a.Kind == ActionDeleteClaims {
+		sign = "-"
+	}
+	lines := make([]string, 0, len(a.Claims))
+	for _, c := range a.Claims {
+		lines = append(lines, fmt.Sprintf("%s claim %s/%s %s", sign, a.Provider, a.User, c))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Plan is the ordered list of actions bringing Boxer to the desired state.
+type Plan struct {
+	Actions []Action
+}
+
+// String formats the plan for dry-run output, one change per line.
+func (p Plan) String() string {
+	if len(p.Actions) == 0 {
+		return "no changes"
+	}
+	lines := make([]string, 0, len(p.Actions))
+	for _, a := range p.Actions {
+		lines = append(lines, a.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ReconcileOptions controls how a desired state is reconciled.
+type ReconcileOptions struct {
+	DryRun bool // Only compute the plan without applying it
+}
+
+// Reconcile brings the users and claims of Boxer to the desired state and returns the plan it applied.
+// With DryRun set the plan is returned without being applied.
+func (s Service) Reconcile(ctx context.Context, desired DesiredState, opts ReconcileOptions) (*Plan, error) {
+	plan, err := s.Plan(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+	return plan, s.Apply(ctx, *plan)
+}
+
+// Plan fetches the current claims of every user in the desired state and computes the changes to apply.
+func (s Service) Plan(ctx context.Context, desired DesiredState) (*Plan, error) {
+	plan := &Plan{}
+	for _, provider := range sortedKeys(desired.Providers) {
+		state := desired.Providers[provider]
+		for _, user := range sortedKeys(state.Users) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			wanted, err := ParseClaims(state.Users[user])
+			if err != nil {
+				return nil, fmt.Errorf("invalid claims for %s/%s: %w", provider, user, err)
+			}
+			current, exists, err := s.currentClaims(user, provider)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				plan.Actions = append(plan.Actions, Action{Kind: ActionAddUser, Provider: provider, User: user})
+			}
+			insert, remove := diffClaims(current, wanted)
+			if len(remove) > 0 {
+				plan.Actions = append(plan.Actions, Action{Kind: ActionDeleteClaims, Provider: provider, User: user, Claims: remove})
+			}
+			if len(insert) > 0 {
+				plan.Actions = append(plan.Actions, Action{Kind: ActionInsertClaims, Provider: provider, User: user, Claims: insert})
+			}
+		}
+		for _, user := range state.Absent {
+			if _, ok := state.Users[user]; ok {
+				return nil, fmt.Errorf("user %s/%s is both desired and absent", provider, user)
+			}
+			_, exists, err := s.currentClaims(user, provider)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				plan.Actions = append(plan.Actions, Action{Kind: ActionRemoveUser, Provider: provider, User: user})
+			}
+		}
+	}
+	return plan, nil
+}
+
+// Apply executes the plan in order, stopping at the first failure.
+func (s Service) Apply(ctx context.Context, plan Plan) error {
+	for _, a := range plan.Actions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var err error
+		switch a.Kind {
+		case ActionAddUser:
+			_, err = s.AddUser(a.User, a.Provider)
+		case ActionRemoveUser:
+			_, err = s.RemoveUser(a.User, a.Provider)
+		case ActionInsertClaims:
+			_, err = s.patchClaims(a.User, a.Provider, newPayload(a.Claims, "Insert"))
+		case ActionDeleteClaims:
+			_, err = s.patchClaims(a.User, a.Provider, newPayload(a.Claims, "Delete"))
+		default:
+			err = fmt.Errorf("unknown action %s", a.Kind)
+		}
+		if err != nil {
+			return fmt.Errorf("error applying %s for %s/%s: %w", a.Kind, a.Provider, a.User, err)
+		}
+	}
+	return nil
+}
+
+// currentClaims fetches the claims of a user and reports whether the user exists.
+func (s Service) currentClaims(user string, provider string) (Claims, bool, error) {
+	userClaims, err := s.GetUserClaims(user, provider)
+	if httpclient.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading claims of %s/%s: %w", provider, user, err)
+	}
+	return userClaims.Claims, true, nil
+}
+
+// diffClaims returns the claims to insert and to delete to go from current to wanted.
+// A changed pattern is expressed as deleting the old claim and inserting the new one.
+func diffClaims(current []Claim, wanted []Claim) ([]Claim, []Claim) {
+	currentByPath := make(map[string]string, len(current))
+	for _, c := range current {
+		currentByPath[c.Path] = c.Pattern
+	}
+	wantedByPath := make(map[string]string, len(wanted))
+	for _, c := range wanted {
+		wantedByPath[c.Path] = c.Pattern
+	}
+
+	var insert, remove []Claim
+	for _, c := range wanted {
+		if pattern, ok := currentByPath[c.Path]; !ok || pattern != c.Pattern {
+			insert = append(insert, c)
+		}
+	}
+	for _, c := range current {
+		if pattern, ok := wantedByPath[c.Path]; !ok || pattern != c.Pattern {
+			remove = append(remove, c)
+		}
+	}
+	return insert, remove
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
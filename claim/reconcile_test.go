@@ -0,0 +1,249 @@
+package claim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeBoxer keeps users and their claims in memory and records every request as "METHOD provider/user".
+// Requests listed in fail are answered with 500.
+type fakeBoxer struct {
+	mu       sync.Mutex
+	users    map[string]map[string]string
+	requests []string
+	fail     map[string]bool
+}
+
+func newFakeBoxer(users map[string]map[string]string) *fakeBoxer {
+	if users == nil {
+		users = map[string]map[string]string{}
+	}
+	return &fakeBoxer{users: users, fail: map[string]bool{}}
+}
+
+func (f *fakeBoxer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := strings.TrimPrefix(r.URL.Path, "/claim/")
+	request := r.Method + " " + key
+	if r.Method != http.MethodGet {
+		f.requests = append(f.requests, request)
+	}
+	if f.fail[request] {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	claims, exists := f.users[key]
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		parts := strings.SplitN(key, "/", 2)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"identityProvider": parts[0], "userId": parts[1], "claims": claims})
+	case http.MethodPost:
+		f.users[key] = map[string]string{}
+	case http.MethodDelete:
+		delete(f.users, key)
+	case http.MethodPatch:
+		var payload claimPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || !exists {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for path, pattern := range payload.Claims {
+			if payload.Operation == "Insert" {
+				claims[path] = pattern
+			} else {
+				delete(claims, path)
+			}
+		}
+		f.requests[len(f.requests)-1] += " " + payload.Operation + " " + strings.Join(sortedKeys(payload.Claims), ",")
+	}
+}
+
+// newFakeService starts a fake Boxer with the given users and returns a service targeting it.
+func newFakeService(t *testing.T, users map[string]map[string]string) (*Service, *fakeBoxer) {
+	boxer := newFakeBoxer(users)
+	server := httptest.NewServer(boxer)
+	t.Cleanup(server.Close)
+	service, err := New(Config{ClaimURL: server.URL, GetTokenFunc: func() (string, error) { return "token", nil }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service, boxer
+}
+
+func TestDiffClaims(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name       string
+		current    []Claim
+		wanted     []Claim
+		wantInsert []Claim
+		wantRemove []Claim
+	}{
+		{
+			name:    "No changes",
+			current: []Claim{{Path: "a", Pattern: ".*"}},
+			wanted:  []Claim{{Path: "a", Pattern: ".*"}},
+		},
+		{
+			name:       "Added and removed paths",
+			current:    []Claim{{Path: "a", Pattern: ".*"}, {Path: "b", Pattern: "GET"}},
+			wanted:     []Claim{{Path: "a", Pattern: ".*"}, {Path: "c", Pattern: "GET"}},
+			wantInsert: []Claim{{Path: "c", Pattern: "GET"}},
+			wantRemove: []Claim{{Path: "b", Pattern: "GET"}},
+		},
+		{
+			name:       "Changed pattern",
+			current:    []Claim{{Path: "a", Pattern: "GET"}},
+			wanted:     []Claim{{Path: "a", Pattern: ".*"}},
+			wantInsert: []Claim{{Path: "a", Pattern: ".*"}},
+			wantRemove: []Claim{{Path: "a", Pattern: "GET"}},
+		},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			insert, remove := diffClaims(tt.current, tt.wanted)
+			if !reflect.DeepEqual(insert, tt.wantInsert) || !reflect.DeepEqual(remove, tt.wantRemove) {
+				t.Errorf("diffClaims() = %v, %v, want %v, %v", insert, remove, tt.wantInsert, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	desired := DesiredState{Providers: map[string]ProviderState{
+		"azuread": {
+			Users: map[string][]string{
+				"alice": {"a:.*", "b:GET"},
+				"bob":   {"a:GET"},
+			},
+			Absent: []string{"carol", "dave"},
+		},
+	}}
+	wantPlan := []string{
+		"DeleteClaims azuread/alice c:.*",
+		"InsertClaims azuread/alice b:GET",
+		"AddUser azuread/bob",
+		"InsertClaims azuread/bob a:GET",
+		"RemoveUser azuread/carol",
+	}
+
+	// Define test cases
+	tests := []struct {
+		name         string
+		opts         ReconcileOptions
+		wantRequests []string
+	}{
+		{name: "Dry run"},
+		{
+			name: "Apply",
+			wantRequests: []string{
+				"PATCH azuread/alice Delete c",
+				"PATCH azuread/alice Insert b",
+				"POST azuread/bob",
+				"PATCH azuread/bob Insert a",
+				"DELETE azuread/carol",
+			},
+		},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantRequests == nil {
+				tt.opts.DryRun = true
+			}
+			service, boxer := newFakeService(t, map[string]map[string]string{
+				"azuread/alice": {"a": ".*", "c": ".*"},
+				"azuread/carol": {"a": ".*"},
+			})
+
+			plan, err := service.Reconcile(context.Background(), desired, tt.opts)
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			var got []string
+			for _, a := range plan.Actions {
+				var paths []string
+				for _, c := range a.Claims {
+					paths = append(paths, c.String())
+				}
+				got = append(got, strings.TrimSpace(string(a.Kind)+" "+a.Provider+"/"+a.User+" "+strings.Join(paths, ",")))
+			}
+			if !reflect.DeepEqual(got, wantPlan) {
+				t.Errorf("Reconcile() plan = %v, want %v", got, wantPlan)
+			}
+			if !reflect.DeepEqual(boxer.requests, tt.wantRequests) {
+				t.Errorf("requests = %v, want %v", boxer.requests, tt.wantRequests)
+			}
+			if tt.opts.DryRun {
+				return
+			}
+
+			// Reconciling again finds nothing to change
+			plan, err = service.Plan(context.Background(), desired)
+			if err != nil || len(plan.Actions) != 0 {
+				t.Errorf("Plan() after Apply = %v, %v, want no changes", plan, err)
+			}
+		})
+	}
+}
+
+func TestPlanRejectsInvalidState(t *testing.T) {
+	service, _ := newFakeService(t, nil)
+
+	// Define test cases
+	tests := []struct {
+		name  string
+		state ProviderState
+	}{
+		{name: "Invalid claim", state: ProviderState{Users: map[string][]string{"alice": {"a"}}}},
+		{name: "Desired and absent", state: ProviderState{Users: map[string][]string{"alice": {}}, Absent: []string{"alice"}}},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Plan(context.Background(), DesiredState{Providers: map[string]ProviderState{"azuread": tt.state}})
+			if err == nil {
+				t.Errorf("Plan() accepted an invalid state")
+			}
+		})
+	}
+}
+
+func TestApplyStopsAtFirstFailure(t *testing.T) {
+	service, boxer := newFakeService(t, nil)
+	boxer.fail["POST azuread/bob"] = true
+
+	plan := Plan{Actions: []Action{
+		{Kind: ActionAddUser, Provider: "azuread", User: "alice"},
+		{Kind: ActionAddUser, Provider: "azuread", User: "bob"},
+		{Kind: ActionAddUser, Provider: "azuread", User: "carol"},
+	}}
+	if err := service.Apply(context.Background(), plan); err == nil {
+		t.Fatalf("Apply() succeeded although an action failed")
+	}
+	users := make([]string, 0, len(boxer.users))
+	for user := range boxer.users {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	if !reflect.DeepEqual(users, []string{"azuread/alice"}) {
+		t.Errorf("users = %v, want only the users created before the failure", users)
+	}
+}
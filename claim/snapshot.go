@@ -0,0 +1,154 @@
+package claim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/shared/file"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by Export.
+const SnapshotVersion = 1
+
+// Snapshot captures the claims of a set of users of an identity provider at a point in time.
+type Snapshot struct {
+	Version   int                `json:"version"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Provider  string             `json:"provider"`
+	Users     map[string][]Claim `json:"users"`
+}
+
+// Export captures the current claims of the given users. Users that do not exist are left out of the snapshot.
+func (s Service) Export(ctx context.Context, provider string, users []string) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Version:   SnapshotVersion,
+		CreatedAt: time.Now().UTC(),
+		Provider:  provider,
+		Users:     make(map[string][]Claim, len(users)),
+	}
+	var errs []error
+	for _, user := range users {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		claims, exists, err := s.currentClaims(user, provider)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if exists {
+			snapshot.Users[user] = claims
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return snapshot, nil
+}
+
+// Import replays the snapshot so that its users end up with exactly the claims it contains,
+// and returns the plan applied. With DryRun set the plan is returned without being applied.
+func (s Service) Import(ctx context.Context, snapshot Snapshot, opts ReconcileOptions) (*Plan, error) {
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+	return s.Reconcile(ctx, snapshot.DesiredState(), opts)
+}
+
+// DesiredState converts the snapshot into a desired state for Reconcile.
+func (snapshot Snapshot) DesiredState() DesiredState {
+	users := make(map[string][]string, len(snapshot.Users))
+	for user, claims := range snapshot.Users {
+		users[user] = make([]string, 0, len(claims))
+		for _, c := range claims {
+			users[user] = append(users[user], c.String())
+		}
+	}
+	return DesiredState{Providers: map[string]ProviderState{snapshot.Provider: {Users: users}}}
+}
+
+// Write stores the snapshot as JSON or YAML, picking the format from the file extension.
+func (snapshot Snapshot) Write(path string) error {
+	return file.WriteStructured(path, snapshot)
+}
+
+// ReadSnapshot loads a snapshot written by Snapshot.Write.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := file.ReadStructured(path, &snapshot); err != nil {
+		return nil, fmt.Errorf("error loading snapshot: %w", err)
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d in %s", snapshot.Version, path)
+	}
+	return &snapshot, nil
+}
+
+// UserDiff lists the claims of a user that differ between two snapshots.
+type UserDiff struct {
+	Added   []Claim // Claims present only in the second snapshot
+	Removed []Claim // Claims present only in the first snapshot
+}
+
+// SnapshotDiff describes the drift between two snapshots.
+type SnapshotDiff struct {
+	OnlyInFirst  []string
+	OnlyInSecond []string
+	Changed      map[string]UserDiff
+}
+
+// IsEmpty reports whether the snapshots hold the same users and claims.
+func (d SnapshotDiff) IsEmpty() bool {
+	return len(d.OnlyInFirst) == 0 && len(d.OnlyInSecond) == 0 && len(d.Changed) == 0
+}
+
+// String formats the diff, one change per line.
+func (d SnapshotDiff) String() string {
+	if d.IsEmpty() {
+		return "no differences"
+	}
+	var lines []string
+	for _, user := range d.OnlyInFirst {
+		lines = append(lines, "- user "+user)
+	}
+	for _, user := range d.OnlyInSecond {
+		lines = append(lines, "+ user "+user)
+	}
+	for _, user := range sortedKeys(d.Changed) {
+		for _, c := range d.Changed[user].Removed {
+			lines = append(lines, fmt.Sprintf("- claim %s %s", user, c))
+		}
+		for _, c := range d.Changed[user].Added {
+			lines = append(lines, fmt.Sprintf("+ claim %s %s", user, c))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiffSnapshots compares two snapshots, i.e. of a test and a production Boxer instance.
+// Snapshots of different identity providers hold different users and are rejected.
+func DiffSnapshots(first Snapshot, second Snapshot) (SnapshotDiff, error) {
+	if first.Provider != second.Provider {
+		return SnapshotDiff{}, fmt.Errorf("cannot compare snapshots of providers %q and %q", first.Provider, second.Provider)
+	}
+	diff := SnapshotDiff{Changed: make(map[string]UserDiff)}
+	for _, user := range sortedKeys(first.Users) {
+		if _, ok := second.Users[user]; !ok {
+			diff.OnlyInFirst = append(diff.OnlyInFirst, user)
+		}
+	}
+	for _, user := range sortedKeys(second.Users) {
+		firstClaims, ok := first.Users[user]
+		if !ok {
+			diff.OnlyInSecond = append(diff.OnlyInSecond, user)
+			continue
+		}
+		added, removed := diffClaims(firstClaims, second.Users[user])
+		if len(added) > 0 || len(removed) > 0 {
+			diff.Changed[user] = UserDiff{Added: added, Removed: removed}
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,100 @@
+package claim
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snapshot := Snapshot{
+		Version:   SnapshotVersion,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Provider:  "azuread",
+		Users: map[string][]Claim{
+			"alice": {{Path: `data/a\.b`, Pattern: "GET"}, {Path: "a:b", Pattern: "https?://.*"}},
+			"bob":   {},
+		},
+	}
+
+	// Define test cases
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "JSON", file: "claims.json"},
+		{name: "YAML", file: "claims.yaml"},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := snapshot.Write(path); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			got, err := ReadSnapshot(path)
+			if err != nil {
+				t.Fatalf("ReadSnapshot() error = %v", err)
+			}
+			if !got.CreatedAt.Equal(snapshot.CreatedAt) || got.Provider != snapshot.Provider || len(got.Users) != 2 ||
+				!reflect.DeepEqual(got.Users["alice"], snapshot.Users["alice"]) || len(got.Users["bob"]) != 0 {
+				t.Errorf("ReadSnapshot() = %+v, want %+v", got, snapshot)
+			}
+		})
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	first := Snapshot{Provider: "azuread", Users: map[string][]Claim{
+		"alice": {{Path: "a", Pattern: ".*"}, {Path: "b", Pattern: "GET"}},
+		"bob":   {{Path: "a", Pattern: ".*"}},
+		"carol": {{Path: "a", Pattern: ".*"}},
+	}}
+	second := Snapshot{Provider: "azuread", Users: map[string][]Claim{
+		"alice": {{Path: "a", Pattern: ".*"}, {Path: "b", Pattern: ".*"}},
+		"carol": {{Path: "a", Pattern: ".*"}},
+		"dave":  {},
+	}}
+
+	diff, err := DiffSnapshots(first, second)
+	if err != nil {
+		t.Fatalf("DiffSnapshots() error = %v", err)
+	}
+	want := "- user bob\n+ user dave\n- claim alice b:GET\n+ claim alice b:.*"
+	if diff.String() != want {
+		t.Errorf("DiffSnapshots() = %q, want %q", diff.String(), want)
+	}
+	if same, _ := DiffSnapshots(first, first); !same.IsEmpty() {
+		t.Errorf("DiffSnapshots() of a snapshot with itself = %v", same)
+	}
+
+	second.Provider = "okta"
+	if _, err := DiffSnapshots(first, second); err == nil {
+		t.Errorf("DiffSnapshots() accepted snapshots of different providers")
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	service, boxer := newFakeService(t, map[string]map[string]string{
+		"azuread/alice": {"a:b": ".*"},
+	})
+
+	snapshot, err := service.Export(context.Background(), "azuread", []string{"alice", "missing"})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(snapshot.Users) != 1 || !reflect.DeepEqual(snapshot.Users["alice"], []Claim{{Path: "a:b", Pattern: ".*"}}) {
+		t.Errorf("Export() = %+v", snapshot)
+	}
+
+	delete(boxer.users, "azuread/alice")
+	if _, err := service.Import(context.Background(), *snapshot, ReconcileOptions{}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if !reflect.DeepEqual(boxer.users["azuread/alice"], map[string]string{"a:b": ".*"}) {
+		t.Errorf("users after Import() = %v", boxer.users)
+	}
+}
@@ -0,0 +1,275 @@
+// Package config loads the configuration of every service from a JSON or YAML file with named profiles and ESD_* environment variables.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/algorithm"
+	"github.com/SneaksAndData/esd-services-api-client-go/auth"
+	"github.com/SneaksAndData/esd-services-api-client-go/claim"
+	"github.com/SneaksAndData/esd-services-api-client-go/dsr"
+	"github.com/SneaksAndData/esd-services-api-client-go/environment"
+	"github.com/SneaksAndData/esd-services-api-client-go/shared/file"
+	"github.com/SneaksAndData/esd-services-api-client-go/spark"
+	"github.com/go-playground/validator/v10"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultAuthProvider is used when no auth provider is configured.
+const defaultAuthProvider = "chain"
+
+// Config configures the auth provider and the endpoints of every service.
+// Endpoints that are not set are taken from the Env profile, see the environment package.
+type Config struct {
+	Env               string      `json:"env" validate:"omitempty,environment"`
+	Auth              auth.Config `json:"auth"` // Provider defaults to chain, Env defaults to the Env above
+	BeastURL          string      `json:"beastUrl"`
+	CrystalURL        string      `json:"crystalUrl"`
+	CrystalAPIVersion string      `json:"crystalApiVersion" validate:"required_with=CrystalURL"`
+	ClaimURL          string      `json:"claimUrl"`
+	DSRURL            string      `json:"dsrUrl"`
+}
+
+// Options selects the config file and profile to load.
+type Options struct {
+	Path    string // JSON or YAML config file, only environment variables are read when empty
+	Profile string // Profile to apply, defaults to ESD_PROFILE and then to the profile key of the file
+}
+
+// envVars maps the environment variables read by FromEnv to the fields they set.
+// Variables holding lists, i.e. scopes, are comma-separated.
+var envVars = map[string]func(c *Config, value string) error{
+	"ESD_ENV":            setString(func(c *Config) *string { return &c.Env }),
+	"ESD_AUTH_ENV":       setString(func(c *Config) *string { return &c.Auth.Env }),
+	"ESD_AUTH_PROVIDER":  setString(func(c *Config) *string { return &c.Auth.Provider }),
+	"ESD_AUTH_TOKEN_URL": setString(func(c *Config) *string { return &c.Auth.TokenURL }),
+	"ESD_AUTH_AZURE_CREDENTIAL": func(c *Config, value string) error {
+		c.Auth.Azure.Credential = auth.AzureCredential(value)
+		return nil
+	},
+	"ESD_AUTH_AZURE_TENANT_ID":            setString(func(c *Config) *string { return &c.Auth.Azure.TenantID }),
+	"ESD_AUTH_AZURE_CLIENT_ID":            setString(func(c *Config) *string { return &c.Auth.Azure.ClientID }),
+	"ESD_AUTH_AZURE_CLIENT_SECRET":        setString(func(c *Config) *string { return &c.Auth.Azure.ClientSecret }),
+	"ESD_AUTH_AZURE_SCOPES":               setList(func(c *Config) *[]string { return &c.Auth.Azure.Scopes }),
+	"ESD_AUTH_AZURE_CERTIFICATE_PATH":     setString(func(c *Config) *string { return &c.Auth.Azure.CertificatePath }),
+	"ESD_AUTH_AZURE_CERTIFICATE_PASSWORD": setString(func(c *Config) *string { return &c.Auth.Azure.CertificatePassword }),
+	"ESD_AUTH_AZURE_TOKEN_FILE_PATH":      setString(func(c *Config) *string { return &c.Auth.Azure.TokenFilePath }),
+	"ESD_AUTH_KUBERNETES_TOKEN_PATH":      setString(func(c *Config) *string { return &c.Auth.Kubernetes.TokenPath }),
+	"ESD_AUTH_OIDC_TOKEN_ENDPOINT":        setString(func(c *Config) *string { return &c.Auth.OIDC.TokenEndpoint }),
+	"ESD_AUTH_OIDC_CLIENT_ID":             setString(func(c *Config) *string { return &c.Auth.OIDC.ClientID }),
+	"ESD_AUTH_OIDC_CLIENT_SECRET":         setString(func(c *Config) *string { return &c.Auth.OIDC.ClientSecret }),
+	"ESD_AUTH_OIDC_SCOPES":                setList(func(c *Config) *[]string { return &c.Auth.OIDC.Scopes }),
+	"ESD_AUTH_OIDC_AUDIENCE":              setString(func(c *Config) *string { return &c.Auth.OIDC.Audience }),
+	"ESD_AUTH_OIDC_SUBJECT_TOKEN_PATH":    setString(func(c *Config) *string { return &c.Auth.OIDC.SubjectTokenPath }),
+	"ESD_AUTH_STATIC_ENV_VAR":             setString(func(c *Config) *string { return &c.Auth.Static.EnvVar }),
+	"ESD_AUTH_CHAIN_SOURCES": func(c *Config, value string) error {
+		c.Auth.Chain.Sources = nil
+		for _, source := range splitList(value) {
+			c.Auth.Chain.Sources = append(c.Auth.Chain.Sources, auth.ChainSource(source))
+		}
+		return nil
+	},
+	"ESD_AUTH_CHAIN_KUBERNETES_PROVIDER": setString(func(c *Config) *string { return &c.Auth.Chain.KubernetesProvider }),
+	"ESD_AUTH_CHAIN_ENV_PROVIDER":        setString(func(c *Config) *string { return &c.Auth.Chain.EnvProvider }),
+	"ESD_AUTH_CACHE": func(c *Config, value string) (err error) {
+		c.Auth.Cache.Enabled, err = strconv.ParseBool(value)
+		return err
+	},
+	"ESD_AUTH_CACHE_DIR":      setString(func(c *Config) *string { return &c.Auth.Cache.Dir }),
+	"ESD_BEAST_URL":           setString(func(c *Config) *string { return &c.BeastURL }),
+	"ESD_CRYSTAL_URL":         setString(func(c *Config) *string { return &c.CrystalURL }),
+	"ESD_CRYSTAL_API_VERSION": setString(func(c *Config) *string { return &c.CrystalAPIVersion }),
+	"ESD_CLAIM_URL":           setString(func(c *Config) *string { return &c.ClaimURL }),
+	"ESD_DSR_URL":             setString(func(c *Config) *string { return &c.DSRURL }),
+}
+
+func setString(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setList(field func(c *Config) *[]string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = splitList(value)
+		return nil
+	}
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Load reads the config file, applies the selected profile and the ESD_* environment variables on top,
+// fills in defaults and validates the result.
+//
+// Settings at the top level of the file are shared by all profiles, and a profile overrides them key by key:
+//
+//	profile: test
+//	auth:
+//	  provider: chain
+//	profiles:
+//	  test:
+//	    env: test
+//	  production:
+//	    env: production
+func Load(options Options) (*Config, error) {
+	var c Config
+	if options.Path != "" {
+		profile := options.Profile
+		if profile == "" {
+			profile = os.Getenv("ESD_PROFILE")
+		}
+		if err := readProfile(options.Path, profile, &c); err != nil {
+			return nil, err
+		}
+	}
+	if err := FromEnv(&c); err != nil {
+		return nil, err
+	}
+	c.applyDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// FromEnv overrides the fields of c with the ESD_* environment variables that are set, i.e. ESD_ENV and ESD_AUTH_PROVIDER,
+// see EnvVars for the full list.
+func FromEnv(c *Config) error {
+	var errs []error
+	for _, name := range EnvVars() {
+		if value, ok := os.LookupEnv(name); ok {
+			if err := envVars[name](c, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EnvVars returns the names of the environment variables read by FromEnv in alphabetical order.
+func EnvVars() []string {
+	names := make([]string, 0, len(envVars))
+	for name := range envVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readProfile decodes the shared settings of the file merged with the selected profile into c.
+func readProfile(path string, profile string, c *Config) error {
+	var document map[string]interface{}
+	if err := file.ReadStructured(path, &document); err != nil {
+		return err
+	}
+	profiles, _ := document["profiles"].(map[string]interface{})
+	if profile == "" {
+		profile, _ = document["profile"].(string)
+	}
+	delete(document, "profiles")
+	delete(document, "profile")
+
+	if profile != "" {
+		selected, ok := profiles[profile].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("profile %q is not defined in %s", profile, path)
+		}
+		document = merge(document, selected)
+	}
+
+	content, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, c); err != nil {
+		return fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+// merge returns base with overrides merged on top, merging nested objects key by key.
+func merge(base map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	for k, v := range overrides {
+		overrideMap, isMap := v.(map[string]interface{})
+		baseMap, wasMap := base[k].(map[string]interface{})
+		if isMap && wasMap {
+			base[k] = merge(baseMap, overrideMap)
+			continue
+		}
+		base[k] = v
+	}
+	return base
+}
+
+func (c *Config) applyDefaults() {
+	if c.Auth.Provider == "" {
+		c.Auth.Provider = defaultAuthProvider
+	}
+	if c.Auth.Env == "" {
+		c.Auth.Env = c.Env
+	}
+}
+
+// Validate checks that the environments are registered, that Boxer can be reached and that Crystal URLs come with an API version.
+func (c Config) Validate() error {
+	validate := validator.New()
+	if err := validate.RegisterValidation("environment", func(fl validator.FieldLevel) bool {
+		_, err := environment.Lookup(fl.Field().String())
+		return err == nil
+	}); err != nil {
+		return err
+	}
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		c := sl.Current().Interface().(Config)
+		if c.Auth.TokenURL == "" && c.Auth.Env == "" && c.Env == "" {
+			sl.ReportError(c.Auth.TokenURL, "Auth.TokenURL", "TokenURL", "required_without_env", "")
+		}
+		if _, err := environment.Lookup(c.Auth.Env); c.Auth.Env != "" && err != nil {
+			sl.ReportError(c.Auth.Env, "Auth.Env", "Env", "environment", "")
+		}
+	}, Config{})
+	if err := validate.Struct(c); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
+
+// AuthConfig returns the auth config with defaults applied.
+func (c Config) AuthConfig() auth.Config {
+	c.applyDefaults()
+	return c.Auth
+}
+
+// SparkConfig returns the config of the Beast service.
+func (c Config) SparkConfig(getToken func() (string, error)) spark.Config {
+	return spark.Config{BaseURL: c.BeastURL, Env: c.Env, GetTokenFunc: getToken}
+}
+
+// AlgorithmConfig returns the config of the Crystal service.
+func (c Config) AlgorithmConfig(getToken func() (string, error)) algorithm.Config {
+	return algorithm.Config{SchedulerURL: c.CrystalURL, APIVersion: c.CrystalAPIVersion, Env: c.Env, GetTokenFunc: getToken}
+}
+
+// ClaimConfig returns the config of the Boxer claim service.
+func (c Config) ClaimConfig(getToken func() (string, error)) claim.Config {
+	return claim.Config{ClaimURL: c.ClaimURL, Env: c.Env, GetTokenFunc: getToken}
+}
+
+// DSRConfig returns the config of the DSR service.
+func (c Config) DSRConfig(getToken func() (string, error)) dsr.Config {
+	return dsr.Config{DsrBaseUrl: c.DSRURL, Env: c.Env, GetTokenFunc: getToken}
+}
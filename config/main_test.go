@@ -0,0 +1,118 @@
+package config
+
+import (
+	"github.com/SneaksAndData/esd-services-api-client-go/environment"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfig = `
+profile: test
+auth:
+  provider: azuread
+  azure:
+    tenantId: tenant
+profiles:
+  test:
+    env: test
+  production:
+    env: production
+    auth:
+      azure:
+        clientId: client
+  local:
+    auth:
+      provider: static-local
+      tokenUrl: http://localhost:8080
+    crystalUrl: http://localhost:8081
+`
+
+func TestLoad(t *testing.T) {
+	environment.Register("test", environment.Profile{BoxerTokenURL: "https://boxer.test.example.com"})
+	environment.Register("production", environment.Profile{BoxerTokenURL: "https://boxer.example.com"})
+	path := filepath.Join(t.TempDir(), "esd.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Define test cases
+	tests := []struct {
+		name    string
+		profile string
+		env     map[string]string
+		check   func(t *testing.T, c *Config)
+		wantErr bool
+	}{
+		{
+			name: "Default profile",
+			check: func(t *testing.T, c *Config) {
+				if c.Env != "test" || c.Auth.Env != "test" || c.Auth.Provider != "azuread" {
+					t.Errorf("Load() = %+v, want the test profile", c)
+				}
+			},
+		},
+		{
+			name:    "Profile merged with shared settings",
+			profile: "production",
+			check: func(t *testing.T, c *Config) {
+				if c.Env != "production" || c.Auth.Azure.TenantID != "tenant" || c.Auth.Azure.ClientID != "client" {
+					t.Errorf("Load() = %+v, want shared and profile settings", c.Auth.Azure)
+				}
+			},
+		},
+		{
+			name: "Environment variables",
+			env:  map[string]string{"ESD_PROFILE": "production", "ESD_AUTH_PROVIDER": "chain", "ESD_DSR_URL": "dsr.example.com"},
+			check: func(t *testing.T, c *Config) {
+				if c.Env != "production" || c.Auth.Provider != "chain" || c.DSRConfig(nil).DsrBaseUrl != "dsr.example.com" {
+					t.Errorf("Load() = %+v, want environment overrides", c)
+				}
+			},
+		},
+		{
+			name: "Auth environment variables",
+			env: map[string]string{
+				"ESD_AUTH_AZURE_CLIENT_ID":       "override",
+				"ESD_AUTH_AZURE_CLIENT_SECRET":   "secret",
+				"ESD_AUTH_AZURE_SCOPES":          "api://boxer/.default, openid",
+				"ESD_AUTH_KUBERNETES_TOKEN_PATH": "/var/run/secrets/tokens/boxer",
+				"ESD_AUTH_CHAIN_SOURCES":         "kubernetes,env",
+				"ESD_AUTH_CACHE":                 "true",
+			},
+			check: func(t *testing.T, c *Config) {
+				a := c.AuthConfig()
+				if a.Azure.TenantID != "tenant" || a.Azure.ClientID != "override" || a.Azure.ClientSecret != "secret" ||
+					len(a.Azure.Scopes) != 2 || a.Azure.Scopes[1] != "openid" || a.Kubernetes.TokenPath != "/var/run/secrets/tokens/boxer" ||
+					len(a.Chain.Sources) != 2 || a.Chain.Sources[1] != "env" || !a.Cache.Enabled {
+					t.Errorf("Load() auth = %+v, want environment overrides", a)
+				}
+			},
+		},
+		{name: "Invalid boolean", env: map[string]string{"ESD_AUTH_CACHE": "sometimes"}, wantErr: true},
+		{name: "Unknown auth environment", env: map[string]string{"ESD_AUTH_ENV": "qa"}, wantErr: true},
+		{name: "Crystal URL without API version", profile: "local", wantErr: true},
+		{name: "Unknown environment", env: map[string]string{"ESD_ENV": "qa"}, wantErr: true},
+		{name: "Unknown profile", profile: "staging", wantErr: true},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			got, err := Load(Options{Path: path, Profile: tt.profile})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+		})
+	}
+
+	if _, err := Load(Options{}); err == nil {
+		t.Errorf("Load() without a token URL or environment succeeded")
+	}
+}
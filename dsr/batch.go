@@ -0,0 +1,261 @@
+package dsr
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultBatchConcurrency = 4
+	utf8BOM                 = "\ufeff"
+)
+
+// InputFormat is the format of a batch lookup input.
+type InputFormat string
+
+const (
+	FormatCSV   InputFormat = "csv"   // Comma separated values with a header row
+	FormatJSONL InputFormat = "jsonl" // One JSON object per line
+)
+
+// BatchOptions controls a batch lookup.
+type BatchOptions struct {
+	Format InputFormat
+	// EmailField is the CSV column or JSON field holding the email, defaults to "email".
+	EmailField string
+	// StripPlusAddressing removes the "+tag" suffix of the local part, so that user+tag@example.com is user@example.com.
+	StripPlusAddressing bool
+	// Concurrency bounds the number of lookups running at the same time, defaults to 4.
+	Concurrency int
+	// Report receives one JSON line per unique email, in input order, followed by one line per invalid value.
+	Report io.Writer
+	// HashSalt, when set, replaces emails in the report with their HMAC-SHA256 keyed with the salt,
+	// so that the report itself does not contain personal data.
+	HashSalt string
+}
+
+// BatchEntry is a single line of a batch lookup report.
+type BatchEntry struct {
+	Email        string   `json:"email"`
+	Status       string   `json:"status,omitempty"`
+	TotalRecords int      `json:"totalRecords"`
+	Datasets     []string `json:"datasets,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// BatchSummary counts the outcomes of a batch lookup.
+type BatchSummary struct {
+	Read       int // Emails read from the input
+	Duplicates int // Emails skipped because they normalize to an email already read
+	Invalid    int // Values that are not email addresses
+	Found      int // Emails with at least one record
+	Failed     int // Lookups that returned an error
+}
+
+// BatchLookup reads emails from r, normalizes and deduplicates them, looks each of them up and writes a report.
+func (s Service) BatchLookup(ctx context.Context, r io.Reader, opts BatchOptions) (*BatchSummary, error) {
+	if opts.Report == nil {
+		return nil, fmt.Errorf("batch lookup requires a report writer")
+	}
+	if opts.EmailField == "" {
+		opts.EmailField = "email"
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultBatchConcurrency
+	}
+
+	values, err := readEmails(r, opts.Format, opts.EmailField)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &BatchSummary{Read: len(values)}
+	seen := make(map[string]bool, len(values))
+	var emails []string
+	var invalid []string
+	for _, value := range values {
+		email, ok := NormalizeEmail(value, opts.StripPlusAddressing)
+		switch {
+		case !ok:
+			summary.Invalid++
+			invalid = append(invalid, value)
+		case seen[email]:
+			summary.Duplicates++
+		default:
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+
+	entries := s.lookupAll(ctx, emails, opts.Concurrency)
+
+	encoder := json.NewEncoder(opts.Report)
+	for _, entry := range entries {
+		if entry.Error != "" {
+			summary.Failed++
+		} else if entry.TotalRecords > 0 {
+			summary.Found++
+		}
+		entry.Email = maskEmail(entry.Email, opts.HashSalt)
+		if err := encoder.Encode(entry); err != nil {
+			return nil, fmt.Errorf("error writing report: %w", err)
+		}
+	}
+	for _, value := range invalid {
+		if err := encoder.Encode(BatchEntry{Email: maskEmail(value, opts.HashSalt), Error: "invalid email"}); err != nil {
+			return nil, fmt.Errorf("error writing report: %w", err)
+		}
+	}
+	return summary, nil
+}
+
+// lookupAll looks up emails with bounded concurrency, keeping the input order.
+func (s Service) lookupAll(ctx context.Context, emails []string, concurrency int) []BatchEntry {
+	entries := make([]BatchEntry, len(emails))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				entries[i] = s.lookup(ctx, emails[i])
+			}
+		}()
+	}
+	for i := range emails {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return entries
+}
+
+func (s Service) lookup(ctx context.Context, email string) BatchEntry {
+	entry := BatchEntry{Email: email}
+	if err := ctx.Err(); err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	result, err := s.GetDSRResult(email)
+	if err != nil {
+		// The error embeds the request URL, which contains the email.
+		entry.Error = strings.NewReplacer(url.PathEscape(email), "<email>", email, "<email>").Replace(err.Error())
+		return entry
+	}
+	entry.Status = result.Status
+	entry.TotalRecords = result.TotalRecords()
+	entry.Datasets = result.Datasets()
+	return entry
+}
+
+// NormalizeEmail trims and lowercases an email, optionally removing plus-addressing tags.
+// It reports false when the value is not an email address.
+func NormalizeEmail(value string, stripPlusAddressing bool) (string, bool) {
+	email := strings.ToLower(strings.TrimSpace(value))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 || strings.ContainsAny(email, " \t") {
+		return "", false
+	}
+	local, domain := email[:at], email[at+1:]
+	if stripPlusAddressing {
+		if plus := strings.Index(local, "+"); plus > 0 {
+			local = local[:plus]
+		}
+	}
+	return local + "@" + domain, true
+}
+
+// maskEmail replaces the email with its salted hash when a salt is given.
+func maskEmail(email string, salt string) string {
+	if salt == "" {
+		return email
+	}
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(email))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// readEmails extracts the email field of every record.
+func readEmails(r io.Reader, format InputFormat, field string) ([]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSVEmails(r, field)
+	case FormatJSONL:
+		return readJSONLEmails(r, field)
+	default:
+		return nil, fmt.Errorf("unsupported input format: %q", format)
+	}
+}
+
+// readCSVEmails reads the emails of the column named field. A leading byte order mark, as written by
+// spreadsheet exports, is not part of the first header cell.
+func readCSVEmails(r io.Reader, field string) ([]string, error) {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(len(utf8BOM)); err == nil && string(bom) == utf8BOM {
+		_, _ = buffered.Discard(len(utf8BOM))
+	}
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+	column := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), field) {
+			column = i
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("CSV header has no %q column", field)
+	}
+
+	var emails []string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return emails, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %w", err)
+		}
+		if column < len(record) && strings.TrimSpace(record[column]) != "" {
+			emails = append(emails, record[column])
+		}
+	}
+}
+
+func readJSONLEmails(r io.Reader, field string) ([]string, error) {
+	var emails []string
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("error parsing line %d: %w", line, err)
+		}
+		email, ok := record[field].(string)
+		if !ok {
+			return nil, fmt.Errorf("line %d has no string %q field", line, field)
+		}
+		emails = append(emails, email)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading JSONL: %w", err)
+	}
+	return emails, nil
+}
@@ -0,0 +1,84 @@
+package dsr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBatchLookup(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if strings.HasSuffix(r.URL.Path, "/found@example.com") {
+			fmt.Fprint(w, `{"status": "COMPLETED", "findings": [{"dataset": "orders", "recordCount": 3}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"status": "COMPLETED", "findings": []}`)
+	}))
+	defer server.Close()
+
+	service, err := New(Config{DsrBaseUrl: server.URL, GetTokenFunc: func() (string, error) { return "token", nil }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := "name,email\nA, Found+newsletter@Example.com \nB,found@example.com\nC,other@example.com\nD,not-an-email\n"
+	var report bytes.Buffer
+	summary, err := service.BatchLookup(context.Background(), strings.NewReader(input), BatchOptions{
+		Format:              FormatCSV,
+		StripPlusAddressing: true,
+		Report:              &report,
+		HashSalt:            "salt",
+	})
+	if err != nil {
+		t.Fatalf("BatchLookup() error = %v", err)
+	}
+
+	want := BatchSummary{Read: 4, Duplicates: 1, Invalid: 1, Found: 1}
+	if *summary != want {
+		t.Errorf("BatchLookup() summary = %+v, want %+v", *summary, want)
+	}
+	if requests != 2 {
+		t.Errorf("lookups = %d, want 2", requests)
+	}
+	if strings.Contains(report.String(), "example.com") || strings.Contains(report.String(), "not-an-email") {
+		t.Errorf("report contains plain emails: %s", report.String())
+	}
+
+	var first BatchEntry
+	if err := json.Unmarshal(bytes.SplitN(report.Bytes(), []byte("\n"), 2)[0], &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Email != maskEmail("found@example.com", "salt") || first.TotalRecords != 3 {
+		t.Errorf("first report entry = %+v", first)
+	}
+}
+
+func TestReadCSVEmails(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Plain header", input: "name,email\nA,a@example.com\n"},
+		{name: "Header with byte order mark", input: "\ufeffemail,name\na@example.com,A\n"},
+		{name: "Quoted header with byte order mark", input: "\ufeff\"email\",\"name\"\na@example.com,A\n"},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emails, err := readCSVEmails(strings.NewReader(tt.input), "email")
+			if err != nil || len(emails) != 1 || emails[0] != "a@example.com" {
+				t.Errorf("readCSVEmails() = %v, %v, want [a@example.com]", emails, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package dsr
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Client is implemented by Service, so code depending on the DSR API can be tested with a mock, see the mocks package.
+type Client interface {
+	GetDSRRequest(email string) (string, error)
+	GetDSRResult(email string) (*DSRResult, error)
+	BatchLookup(ctx context.Context, r io.Reader, opts BatchOptions) (*BatchSummary, error)
+	CreateRequest(input NewRequest) (*Request, error)
+	GetRequest(id string) (*Request, error)
+	ListRequests(status string) ([]Request, error)
+	ListPendingRequests() ([]Request, error)
+	WaitForRequest(ctx context.Context, id string, pollInterval time.Duration) (*Request, error)
+}
+
+var _ Client = (*Service)(nil)
@@ -1,7 +1,10 @@
+// Package dsr provides functionalities to search for and manage data subject requests
 package dsr
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/environment"
 	"github.com/SneaksAndData/esd-services-api-client-go/shared/httpclient"
 	"net/http"
 )
@@ -12,8 +15,9 @@ type Service struct {
 	dsrBaseUrl string
 }
 
+// GetDSRRequest searches for the data of a data subject identified by email and returns the raw response.
 func (s Service) GetDSRRequest(email string) (string, error) {
-	targetURL := s.dsrBaseUrl + "/dsr/" + email
+	targetURL := httpclient.NewURL(s.dsrBaseUrl).Path("dsr", email).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -21,18 +25,40 @@ func (s Service) GetDSRRequest(email string) (string, error) {
 	return string(response), nil
 }
 
+// GetDSRResult searches for the data of a data subject identified by email and returns where it was found.
+func (s Service) GetDSRResult(email string) (*DSRResult, error) {
+	response, err := s.GetDSRRequest(email)
+	if err != nil {
+		return nil, err
+	}
+	var result DSRResult
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return &result, nil
+}
+
 // Config represents the configuration needed to create a new Service instance.
 type Config struct {
 	GetTokenFunc func() (string, error) // Function to retrieve authentication token
-	HTTPClient   *httpclient.Client     // HTTP client to be used by the Service
+	HTTPClient   *httpclient.Client     // Shared HTTP client, GetTokenFunc is ignored when it is set
 	DsrBaseUrl   string                 // Base URL for the DSR API service
+	Env          string                 // Environment whose DSR URL is used when DsrBaseUrl is empty, see the environment package
 }
 
 // New creates a new instance of the Service using the provided Config.
 func New(c Config) (*Service, error) {
+	dsrBaseUrl, err := environment.Resolve(c.DsrBaseUrl, c.Env, func(p environment.Profile) string { return p.DSRURL })
+	if err != nil {
+		return nil, err
+	}
+	dsrBaseUrl, err = httpclient.NormalizeBaseURL(dsrBaseUrl)
+	if err != nil {
+		return nil, err
+	}
 	s := &Service{
-		httpClient: httpclient.NewClient(c.GetTokenFunc),
-		dsrBaseUrl: c.DsrBaseUrl,
+		httpClient: httpclient.ClientOrNew(c.HTTPClient, c.GetTokenFunc),
+		dsrBaseUrl: dsrBaseUrl,
 	}
 	return s, nil
 }
@@ -0,0 +1,53 @@
+package dsr
+
+// Search statuses reported by the DSR API.
+const (
+	StatusPending   = "PENDING"
+	StatusRunning   = "RUNNING"
+	StatusCompleted = "COMPLETED"
+	StatusFailed    = "FAILED"
+)
+
+// DSRResult describes where the data of a data subject was found.
+type DSRResult struct {
+	Email    string    `json:"email"`
+	Status   string    `json:"status"`
+	Findings []Finding `json:"findings"`
+}
+
+// Finding describes the data of a data subject found in a single dataset.
+type Finding struct {
+	// Dataset is the name of the dataset holding the data.
+	Dataset string `json:"dataset"`
+	// Location is the fully qualified path to the data, i.e. abfss://..., s3a://... etc.
+	Location string `json:"location"`
+	// RecordCount is the number of records referring to the data subject.
+	RecordCount int `json:"recordCount"`
+	// Status is the search status for this dataset.
+	Status string `json:"status"`
+}
+
+// IsCompleted reports whether the search has finished in all datasets.
+func (r DSRResult) IsCompleted() bool {
+	return r.Status == StatusCompleted
+}
+
+// TotalRecords returns the number of records found across all datasets.
+func (r DSRResult) TotalRecords() int {
+	total := 0
+	for _, f := range r.Findings {
+		total += f.RecordCount
+	}
+	return total
+}
+
+// Datasets returns the names of datasets holding at least one record of the data subject.
+func (r DSRResult) Datasets() []string {
+	var datasets []string
+	for _, f := range r.Findings {
+		if f.RecordCount > 0 {
+			datasets = append(datasets, f.Dataset)
+		}
+	}
+	return datasets
+}
@@ -0,0 +1,50 @@
+package dsr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestGetDSRResult(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/dsr_result.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dsr/jane.doe@example.com" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(fixture)
+	}))
+	defer server.Close()
+	service, err := New(Config{DsrBaseUrl: server.URL, GetTokenFunc: func() (string, error) { return "token", nil }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := service.GetDSRResult("jane.doe@example.com")
+	if err != nil {
+		t.Fatalf("GetDSRResult() error = %v", err)
+	}
+	if result.Email != "jane.doe@example.com" || !result.IsCompleted() || len(result.Findings) != 3 {
+		t.Errorf("GetDSRResult() = %+v", result)
+	}
+	want := Finding{Dataset: "orders", Location: "abfss://raw@datalake.dfs.core.windows.net/orders", RecordCount: 12, Status: StatusCompleted}
+	if result.Findings[0] != want {
+		t.Errorf("Findings[0] = %+v, want %+v", result.Findings[0], want)
+	}
+	if result.TotalRecords() != 15 {
+		t.Errorf("TotalRecords() = %d, want 15", result.TotalRecords())
+	}
+	if !reflect.DeepEqual(result.Datasets(), []string{"orders", "support_tickets"}) {
+		t.Errorf("Datasets() = %v", result.Datasets())
+	}
+
+	if _, err := service.GetDSRResult("unknown@example.com"); err == nil {
+		t.Errorf("GetDSRResult() for an unknown subject succeeded")
+	}
+}
@@ -0,0 +1,142 @@
+package dsr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/shared/httpclient"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultDeadline is the time a data subject request has to be answered in when no deadline is given.
+	DefaultDeadline = 30 * 24 * time.Hour
+	// DefaultPollInterval is used by WaitForRequest when no positive poll interval is given.
+	DefaultPollInterval = 10 * time.Second
+)
+
+// RequestKind is the kind of data subject request.
+type RequestKind string
+
+const (
+	KindAccess        RequestKind = "ACCESS"        // Export all data of the data subject
+	KindErasure       RequestKind = "ERASURE"       // Delete all data of the data subject
+	KindRectification RequestKind = "RECTIFICATION" // Correct data of the data subject
+)
+
+// NewRequest defines the request body for creating a data subject request.
+type NewRequest struct {
+	Email string      `json:"email" validate:"required,email"`
+	Kind  RequestKind `json:"kind" validate:"required,oneof=ACCESS ERASURE RECTIFICATION"`
+	// Deadline defaults to DefaultDeadline from now.
+	Deadline time.Time `json:"deadline"`
+	// Corrections maps fields to their corrected values, required for rectification requests.
+	Corrections map[string]string `json:"corrections,omitempty" validate:"required_if=Kind RECTIFICATION"`
+}
+
+// Request describes a data subject request and its progress.
+type Request struct {
+	ID          string            `json:"id"`
+	Email       string            `json:"email"`
+	Kind        RequestKind       `json:"kind"`
+	Status      string            `json:"status"`
+	Deadline    time.Time         `json:"deadline"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	CompletedAt *time.Time        `json:"completedAt"`
+	Corrections map[string]string `json:"corrections,omitempty"`
+	Error       string            `json:"error"`
+}
+
+// IsFinished reports whether the request has completed or failed.
+func (r Request) IsFinished() bool {
+	return r.Status == StatusCompleted || r.Status == StatusFailed
+}
+
+// IsOverdue reports whether the request is still open after its deadline.
+func (r Request) IsOverdue(now time.Time) bool {
+	return !r.IsFinished() && now.After(r.Deadline)
+}
+
+// CreateRequest submits a new data subject request.
+func (s Service) CreateRequest(input NewRequest) (*Request, error) {
+	if err := validator.New().Struct(input); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if input.Deadline.IsZero() {
+		input.Deadline = time.Now().UTC().Add(DefaultDeadline)
+	}
+
+	targetURL := httpclient.NewURL(s.dsrBaseUrl).Path("dsr", "requests").String()
+	response, err := s.httpClient.MakeRequest(http.MethodPost, targetURL, input)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to %s: %w", targetURL, err)
+	}
+	return decodeRequest(response)
+}
+
+// GetRequest fetches a data subject request by its identifier.
+func (s Service) GetRequest(id string) (*Request, error) {
+	targetURL := httpclient.NewURL(s.dsrBaseUrl).Path("dsr", "requests", id).String()
+	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to %s: %w", targetURL, err)
+	}
+	return decodeRequest(response)
+}
+
+// ListRequests returns data subject requests in the given status, or all requests when status is empty.
+func (s Service) ListRequests(status string) ([]Request, error) {
+	u := httpclient.NewURL(s.dsrBaseUrl).Path("dsr", "requests")
+	if status != "" {
+		u.Query("status", status)
+	}
+	targetURL := u.String()
+	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to %s: %w", targetURL, err)
+	}
+	var requests []Request
+	if err := json.Unmarshal(response, &requests); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return requests, nil
+}
+
+// ListPendingRequests returns data subject requests that have not been picked up yet.
+func (s Service) ListPendingRequests() ([]Request, error) {
+	return s.ListRequests(StatusPending)
+}
+
+// WaitForRequest polls a data subject request until it finishes or ctx is done.
+// A pollInterval that is not positive defaults to DefaultPollInterval.
+func (s Service) WaitForRequest(ctx context.Context, id string, pollInterval time.Duration) (*Request, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		request, err := s.GetRequest(id)
+		if err != nil {
+			return nil, err
+		}
+		if request.IsFinished() {
+			return request, nil
+		}
+		select {
+		case <-ctx.Done():
+			return request, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func decodeRequest(response []byte) (*Request, error) {
+	var request Request
+	if err := json.Unmarshal(response, &request); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return &request, nil
+}
@@ -0,0 +1,142 @@
+package dsr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRequestServer serves data subject requests, reporting request "slow" as RUNNING until it was polled three times.
+func newRequestServer(t *testing.T, created chan<- NewRequest) *Service {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/dsr/requests":
+			var input NewRequest
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			created <- input
+			fmt.Fprintf(w, `{"id": "request-1", "email": %q, "kind": %q, "status": "PENDING"}`, input.Email, input.Kind)
+		case r.URL.Path == "/dsr/requests":
+			fmt.Fprintf(w, `[{"id": "request-1", "status": %q}]`, r.URL.Query().Get("status"))
+		case r.URL.Path == "/dsr/requests/slow":
+			status := StatusRunning
+			if atomic.AddInt32(&polls, 1) >= 3 {
+				status = StatusCompleted
+			}
+			fmt.Fprintf(w, `{"id": "slow", "status": %q}`, status)
+		case r.URL.Path == "/dsr/requests/stuck":
+			fmt.Fprint(w, `{"id": "stuck", "status": "RUNNING"}`)
+		case r.URL.Path == "/dsr/requests/failed":
+			fmt.Fprint(w, `{"id": "failed", "status": "FAILED", "error": "dataset unavailable"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	service, err := New(Config{DsrBaseUrl: server.URL, GetTokenFunc: func() (string, error) { return "token", nil }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestCreateRequest(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name    string
+		input   NewRequest
+		wantErr bool
+	}{
+		{name: "Erasure", input: NewRequest{Email: "jane@example.com", Kind: KindErasure}},
+		{name: "Rectification", input: NewRequest{Email: "jane@example.com", Kind: KindRectification, Corrections: map[string]string{"name": "Jane"}}},
+		{name: "Invalid email", input: NewRequest{Email: "jane", Kind: KindAccess}, wantErr: true},
+		{name: "Unknown kind", input: NewRequest{Email: "jane@example.com", Kind: "EXPORT"}, wantErr: true},
+		{name: "Rectification without corrections", input: NewRequest{Email: "jane@example.com", Kind: KindRectification}, wantErr: true},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := make(chan NewRequest, 1)
+			service := newRequestServer(t, created)
+
+			request, err := service.CreateRequest(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(created) != 0 {
+					t.Errorf("CreateRequest() sent an invalid request")
+				}
+				return
+			}
+			sent := <-created
+			if request.ID != "request-1" || request.Kind != tt.input.Kind || request.Status != StatusPending {
+				t.Errorf("CreateRequest() = %+v", request)
+			}
+			if deadline := time.Until(sent.Deadline); deadline < DefaultDeadline-time.Minute || deadline > DefaultDeadline {
+				t.Errorf("sent deadline = %v, want the default deadline", sent.Deadline)
+			}
+		})
+	}
+}
+
+func TestGetAndListRequests(t *testing.T) {
+	service := newRequestServer(t, nil)
+
+	request, err := service.GetRequest("failed")
+	if err != nil || !request.IsFinished() || request.Error != "dataset unavailable" {
+		t.Errorf("GetRequest() = %+v, %v", request, err)
+	}
+	if _, err := service.GetRequest("unknown"); err == nil {
+		t.Errorf("GetRequest() for an unknown request succeeded")
+	}
+
+	requests, err := service.ListPendingRequests()
+	if err != nil || len(requests) != 1 || requests[0].Status != StatusPending {
+		t.Errorf("ListPendingRequests() = %+v, %v", requests, err)
+	}
+}
+
+func TestWaitForRequest(t *testing.T) {
+	service := newRequestServer(t, nil)
+
+	// Define test cases
+	tests := []struct {
+		name         string
+		id           string
+		pollInterval time.Duration
+		timeout      time.Duration
+		wantStatus   string
+		wantErr      bool
+	}{
+		{name: "Finishes after polling", id: "slow", pollInterval: time.Millisecond, timeout: time.Second, wantStatus: StatusCompleted},
+		{name: "Already failed", id: "failed", timeout: time.Second, wantStatus: StatusFailed},
+		{name: "Context done", id: "stuck", pollInterval: time.Millisecond, timeout: 20 * time.Millisecond, wantStatus: StatusRunning, wantErr: true},
+		{name: "Unknown request", id: "unknown", pollInterval: time.Millisecond, timeout: time.Second, wantErr: true},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			request, err := service.WaitForRequest(ctx, tt.id, tt.pollInterval)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitForRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantStatus != "" && (request == nil || request.Status != tt.wantStatus) {
+				t.Errorf("WaitForRequest() = %+v, want status %s", request, tt.wantStatus)
+			}
+		})
+	}
+}
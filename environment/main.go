@@ -0,0 +1,75 @@
+// Package environment maps environment names, i.e. test or production, to the endpoints of every service.
+// No environments are built in: applications Register the profiles of their deployments before using them.
+package environment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Profile holds the endpoints of an environment.
+type Profile struct {
+	BoxerTokenURL     string // Boxer token endpoint, used by auth
+	BoxerClaimURL     string // Boxer claim endpoint, used by claim
+	BeastURL          string // Beast base URL, used by spark
+	CrystalURL        string // Crystal scheduler URL, used by algorithm
+	CrystalAPIVersion string // Crystal API version, used by algorithm
+	DSRURL            string // DSR base URL, used by dsr
+}
+
+var (
+	mu       sync.RWMutex
+	profiles = map[string]Profile{}
+)
+
+// Register adds a profile or replaces an existing one. Names are case-insensitive.
+func Register(name string, p Profile) {
+	mu.Lock()
+	defer mu.Unlock()
+	profiles[strings.ToLower(name)] = p
+}
+
+// Lookup returns the profile registered under name, ignoring case.
+func Lookup(name string) (Profile, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown environment %q, registered environments: %s", name, strings.Join(names(), ", "))
+	}
+	return p, nil
+}
+
+// Names returns the registered environment names in alphabetical order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return names()
+}
+
+func names() []string {
+	result := make([]string, 0, len(profiles))
+	for name := range profiles {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Resolve returns explicit when it is set or env is empty, otherwise the endpoint selected from the profile of env.
+func Resolve(explicit string, env string, endpoint func(Profile) string) (string, error) {
+	if explicit != "" || env == "" {
+		return explicit, nil
+	}
+	p, err := Lookup(env)
+	if err != nil {
+		return "", err
+	}
+	value := endpoint(p)
+	if value == "" {
+		return "", fmt.Errorf("environment %q does not define this endpoint", env)
+	}
+	return value, nil
+}
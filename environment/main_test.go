@@ -0,0 +1,39 @@
+package environment
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	Register("Staging", Profile{BeastURL: "https://beast.staging.example.com"})
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		explicit string
+		env      string
+		want     string
+		wantErr  bool
+	}{
+		{name: "Explicit URL wins", explicit: "https://beast.example.com", env: "staging", want: "https://beast.example.com"},
+		{name: "No environment", want: ""},
+		{name: "No built-in profiles", env: "production", wantErr: true},
+		{name: "Registered profile ignores case", env: "STAGING", want: "https://beast.staging.example.com"},
+		{name: "Unknown environment", env: "qa", wantErr: true},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.explicit, tt.env, func(p Profile) string { return p.BeastURL })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Resolve("", "staging", func(p Profile) string { return p.DSRURL }); err == nil {
+		t.Errorf("Resolve() of an undefined endpoint succeeded")
+	}
+}
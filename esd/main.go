@@ -0,0 +1,119 @@
+// Package esd provides a single client for all ESD services, sharing one auth chain and one HTTP client.
+package esd
+
+import (
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/algorithm"
+	"github.com/SneaksAndData/esd-services-api-client-go/auth"
+	"github.com/SneaksAndData/esd-services-api-client-go/claim"
+	"github.com/SneaksAndData/esd-services-api-client-go/config"
+	"github.com/SneaksAndData/esd-services-api-client-go/dsr"
+	"github.com/SneaksAndData/esd-services-api-client-go/environment"
+	"github.com/SneaksAndData/esd-services-api-client-go/shared/httpclient"
+	"github.com/SneaksAndData/esd-services-api-client-go/spark"
+)
+
+// Config configures the auth provider and the endpoints of every service, see config.Config.
+type Config = config.Config
+
+// LoadConfig reads a JSON or YAML config file, applying its default profile and the ESD_* environment variables, see config.Load.
+func LoadConfig(path string) (Config, error) {
+	c, err := config.Load(config.Options{Path: path})
+	if err != nil {
+		return Config{}, err
+	}
+	return *c, nil
+}
+
+// ConfigFromEnv reads the config from ESD_* environment variables, i.e. ESD_ENV and ESD_AUTH_PROVIDER, see config.EnvVars.
+func ConfigFromEnv() (Config, error) {
+	var c Config
+	if err := config.FromEnv(&c); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// Client gives access to every service through one auth chain and one HTTP client.
+type Client struct {
+	auth      *auth.Service
+	spark     *spark.Service
+	algorithm *algorithm.Service
+	claim     *claim.Service
+	dsr       *dsr.Service
+}
+
+// NewClient creates the auth service and every service with a configured endpoint.
+// The services share the Boxer token until it expires, and request a new one when a service rejects it.
+// Services without an endpoint, neither set explicitly nor in the Env profile, are not created and their accessor returns nil.
+func NewClient(c Config) (*Client, error) {
+	var profile environment.Profile
+	if c.Env != "" {
+		var err error
+		if profile, err = environment.Lookup(c.Env); err != nil {
+			return nil, err
+		}
+	}
+	authService, err := auth.New(c.AuthConfig())
+	if err != nil {
+		return nil, fmt.Errorf("error creating auth service: %w", err)
+	}
+	token := newBoxerToken(authService)
+	httpClient := httpclient.NewClientWithInvalidate(token.get, token.invalidate)
+	client := &Client{auth: authService}
+
+	if c.BeastURL != "" || profile.BeastURL != "" {
+		sparkConfig := c.SparkConfig(nil)
+		sparkConfig.HTTPClient = httpClient
+		if client.spark, err = spark.New(sparkConfig); err != nil {
+			return nil, fmt.Errorf("error creating spark service: %w", err)
+		}
+	}
+	if c.CrystalURL != "" || profile.CrystalURL != "" {
+		algorithmConfig := c.AlgorithmConfig(nil)
+		algorithmConfig.HTTPClient = httpClient
+		if client.algorithm, err = algorithm.New(algorithmConfig); err != nil {
+			return nil, fmt.Errorf("error creating algorithm service: %w", err)
+		}
+	}
+	if c.ClaimURL != "" || profile.BoxerClaimURL != "" {
+		claimConfig := c.ClaimConfig(nil)
+		claimConfig.HTTPClient = httpClient
+		if client.claim, err = claim.New(claimConfig); err != nil {
+			return nil, fmt.Errorf("error creating claim service: %w", err)
+		}
+	}
+	if c.DSRURL != "" || profile.DSRURL != "" {
+		dsrConfig := c.DSRConfig(nil)
+		dsrConfig.HTTPClient = httpClient
+		if client.dsr, err = dsr.New(dsrConfig); err != nil {
+			return nil, fmt.Errorf("error creating dsr service: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// Auth returns the auth service that issues the Boxer tokens used by every service.
+func (c *Client) Auth() *auth.Service {
+	return c.auth
+}
+
+// Spark returns the Beast service, or nil when no Beast URL is configured.
+func (c *Client) Spark() *spark.Service {
+	return c.spark
+}
+
+// Algorithm returns the Crystal service, or nil when no Crystal URL is configured.
+func (c *Client) Algorithm() *algorithm.Service {
+	return c.algorithm
+}
+
+// Claim returns the Boxer claim service, or nil when no claim URL is configured.
+func (c *Client) Claim() *claim.Service {
+	return c.claim
+}
+
+// DSR returns the DSR service, or nil when no DSR URL is configured.
+func (c *Client) DSR() *dsr.Service {
+	return c.dsr
+}
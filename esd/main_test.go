@@ -0,0 +1,124 @@
+package esd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/auth"
+	"github.com/SneaksAndData/esd-services-api-client-go/environment"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewClient(t *testing.T) {
+	// One server stands in for Boxer, Beast and DSR.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token/local":
+			_, _ = w.Write([]byte("boxer-token"))
+		case "/job/logs/run-1":
+			_, _ = w.Write([]byte(`["` + r.Header.Get("Authorization") + `"]`))
+		case "/dsr/user@example.com":
+			_, _ = w.Write([]byte(`{"email": "user@example.com", "status": "COMPLETED"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "esd.yaml")
+	content := "auth:\n  provider: static-local\n  tokenUrl: " + server.URL + "\n  static:\n    token: identity\nbeastUrl: " + server.URL + "\n"
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	t.Setenv("ESD_DSR_URL", server.URL)
+	fromEnv, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv() error = %v", err)
+	}
+	config.DSRURL = fromEnv.DSRURL
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.Algorithm() != nil || client.Claim() != nil {
+		t.Errorf("services without an endpoint were created")
+	}
+	if logs, err := client.Spark().GetLogs("run-1"); err != nil || logs != "Bearer boxer-token" {
+		t.Errorf("Spark().GetLogs() = %q, %v, want the Boxer token", logs, err)
+	}
+	result, err := client.DSR().GetDSRResult("user@example.com")
+	if err != nil || !result.IsCompleted() {
+		t.Errorf("DSR().GetDSRResult() = %v, %v", result, err)
+	}
+}
+
+func TestNewClientSharesBoxerToken(t *testing.T) {
+	encode := base64.RawURLEncoding.EncodeToString
+	var issued int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token/local":
+			n := atomic.AddInt32(&issued, 1)
+			payload := fmt.Sprintf(`{"jti":"token-%d","exp":%d}`, n, time.Now().Add(time.Hour).Unix())
+			_, _ = w.Write([]byte(encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(payload)) + "." + encode([]byte("signature"))))
+		case "/job/logs/revoked":
+			// The first token is revoked before it expires
+			if atomic.LoadInt32(&issued) == 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`["ok"]`))
+		default:
+			_, _ = w.Write([]byte(`["ok"]`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Auth:     auth.Config{Provider: "static-local", TokenURL: server.URL, Static: auth.StaticConfig{Token: "identity"}},
+		BeastURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := client.Spark().GetLogs("run-1"); err != nil {
+			t.Fatalf("Spark().GetLogs() error = %v", err)
+		}
+	}
+	if issued != 1 {
+		t.Errorf("Boxer tokens issued = %d, want 1 shared until it expires", issued)
+	}
+
+	if _, err := client.Spark().GetLogs("revoked"); err != nil || issued != 2 {
+		t.Errorf("Spark().GetLogs() error = %v after %d Boxer tokens, want a new token after the rejection", err, issued)
+	}
+}
+
+func TestNewClientWithPartialProfile(t *testing.T) {
+	environment.Register("staging", environment.Profile{BoxerTokenURL: "https://boxer.staging.example.com", BeastURL: "https://beast.staging.example.com"})
+
+	client, err := NewClient(Config{
+		Env:  "staging",
+		Auth: auth.Config{Provider: "static-local", Static: auth.StaticConfig{Token: "identity"}},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.Spark() == nil {
+		t.Error("Spark() = nil, want the service of the profile endpoint")
+	}
+	if client.Algorithm() != nil || client.Claim() != nil || client.DSR() != nil {
+		t.Error("services missing from the profile were created")
+	}
+}
@@ -0,0 +1,47 @@
+package esd
+
+import (
+	"github.com/SneaksAndData/esd-services-api-client-go/auth"
+	"sync"
+	"time"
+)
+
+// boxerToken keeps the Boxer token in memory until shortly before it expires, so that the services sharing it
+// do not request a new one for every call. Tokens without an exp claim are not kept.
+type boxerToken struct {
+	auth   *auth.Service
+	now    func() time.Time
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func newBoxerToken(authService *auth.Service) *boxerToken {
+	return &boxerToken{auth: authService, now: time.Now}
+}
+
+func (b *boxerToken) get() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token != "" && !auth.ExpiresSoon(b.expiry, b.now()) {
+		return b.token, nil
+	}
+
+	token, err := b.auth.GetBoxerToken()
+	if err != nil {
+		return "", err
+	}
+	b.token, b.expiry = "", time.Time{}
+	if info, err := auth.Inspect(token); err == nil && !info.ExpiresAt.IsZero() {
+		b.token, b.expiry = token, info.ExpiresAt
+	}
+	return token, nil
+}
+
+// invalidate discards the token after a service rejected it, including the copy in the on-disk cache of auth.
+func (b *boxerToken) invalidate() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.token, b.expiry = "", time.Time{}
+	b.auth.InvalidateBoxerToken()
+}
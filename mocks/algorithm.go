@@ -0,0 +1,94 @@
+package mocks
+
+import (
+	"context"
+	"github.com/SneaksAndData/esd-services-api-client-go/algorithm"
+)
+
+// Algorithm mocks algorithm.Client, the Crystal service.
+type Algorithm struct {
+	recorder
+	CreateRunFunc             func(algorithmName string, input algorithm.Payload, tag string) (string, error)
+	CreateRunFromTemplateFunc func(t algorithm.Template, overrides map[string]interface{}, tag string) (string, error)
+	RetrieveRunFunc           func(runID string, algorithmName string) (string, error)
+	RetrieveRunsFunc          func(algorithmName string, tag string) ([]algorithm.RunResult, error)
+	RetrieveRunsByTagFunc     func(tag string) ([]algorithm.TaggedRun, error)
+	RetrieveAlgorithmRunsFunc func(algorithmName string) ([]algorithm.RunResult, error)
+	RetrievePayloadUriFunc    func(runID string, algorithmName string) (*algorithm.PayloadResponse, error)
+	CancelRunFunc             func(algorithmName string, requestId string, initiator string, reason string) (string, error)
+	CancelRunsFunc            func(ctx context.Context, filter algorithm.RunFilter, initiator string, reason string) ([]algorithm.CancelReport, error)
+}
+
+var _ algorithm.Client = (*Algorithm)(nil)
+
+func (m *Algorithm) CreateRun(algorithmName string, input algorithm.Payload, tag string) (string, error) {
+	m.record("CreateRun", algorithmName, input, tag)
+	if m.CreateRunFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.CreateRunFunc(algorithmName, input, tag)
+}
+
+func (m *Algorithm) CreateRunFromTemplate(t algorithm.Template, overrides map[string]interface{}, tag string) (string, error) {
+	m.record("CreateRunFromTemplate", t, overrides, tag)
+	if m.CreateRunFromTemplateFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.CreateRunFromTemplateFunc(t, overrides, tag)
+}
+
+func (m *Algorithm) RetrieveRun(runID string, algorithmName string) (string, error) {
+	m.record("RetrieveRun", runID, algorithmName)
+	if m.RetrieveRunFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.RetrieveRunFunc(runID, algorithmName)
+}
+
+func (m *Algorithm) RetrieveRuns(algorithmName string, tag string) ([]algorithm.RunResult, error) {
+	m.record("RetrieveRuns", algorithmName, tag)
+	if m.RetrieveRunsFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.RetrieveRunsFunc(algorithmName, tag)
+}
+
+func (m *Algorithm) RetrievePayloadUri(runID string, algorithmName string) (*algorithm.PayloadResponse, error) {
+	m.record("RetrievePayloadUri", runID, algorithmName)
+	if m.RetrievePayloadUriFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.RetrievePayloadUriFunc(runID, algorithmName)
+}
+
+func (m *Algorithm) CancelRun(algorithmName string, requestId string, initiator string, reason string) (string, error) {
+	m.record("CancelRun", algorithmName, requestId, initiator, reason)
+	if m.CancelRunFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.CancelRunFunc(algorithmName, requestId, initiator, reason)
+}
+
+func (m *Algorithm) CancelRuns(ctx context.Context, filter algorithm.RunFilter, initiator string, reason string) ([]algorithm.CancelReport, error) {
+	m.record("CancelRuns", ctx, filter, initiator, reason)
+	if m.CancelRunsFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.CancelRunsFunc(ctx, filter, initiator, reason)
+}
+
+func (m *Algorithm) RetrieveRunsByTag(tag string) ([]algorithm.TaggedRun, error) {
+	m.record("RetrieveRunsByTag", tag)
+	if m.RetrieveRunsByTagFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.RetrieveRunsByTagFunc(tag)
+}
+
+func (m *Algorithm) RetrieveAlgorithmRuns(algorithmName string) ([]algorithm.RunResult, error) {
+	m.record("RetrieveAlgorithmRuns", algorithmName)
+	if m.RetrieveAlgorithmRunsFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.RetrieveAlgorithmRunsFunc(algorithmName)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import "github.com/SneaksAndData/esd-services-api-client-go/auth"
+
+// Auth mocks auth.Client, the Boxer token service.
+type Auth struct {
+	recorder
+	GetBoxerTokenFunc        func() (string, error)
+	InvalidateBoxerTokenFunc func()
+	SourceFunc               func() string
+	EnvFunc                  func() string
+}
+
+var _ auth.Client = (*Auth)(nil)
+
+func (m *Auth) GetBoxerToken() (string, error) {
+	m.record("GetBoxerToken")
+	if m.GetBoxerTokenFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.GetBoxerTokenFunc()
+}
+
+func (m *Auth) InvalidateBoxerToken() {
+	m.record("InvalidateBoxerToken")
+	if m.InvalidateBoxerTokenFunc == nil {
+		panic(ErrNotImplemented)
+	}
+	m.InvalidateBoxerTokenFunc()
+}
+
+func (m *Auth) Source() string {
+	m.record("Source")
+	if m.SourceFunc == nil {
+		panic(ErrNotImplemented)
+	}
+	return m.SourceFunc()
+}
+
+func (m *Auth) Env() string {
+	m.record("Env")
+	if m.EnvFunc == nil {
+		panic(ErrNotImplemented)
+	}
+	return m.EnvFunc()
+}
@@ -0,0 +1,143 @@
+package mocks
+
+import (
+	"context"
+	"github.com/SneaksAndData/esd-services-api-client-go/claim"
+)
+
+// Claim mocks claim.Client, the Boxer claim service.
+type Claim struct {
+	recorder
+	GetClaimFunc       func(user string, provider string) (string, error)
+	GetUserClaimsFunc  func(user string, provider string) (*claim.UserClaims, error)
+	AddClaimFunc       func(user string, provider string, claims []string) (string, error)
+	AddClaimsFunc      func(user string, provider string, claims []claim.Claim) (*claim.UserClaims, error)
+	RemoveClaimFunc    func(user string, provider string, claims []string) (string, error)
+	RemoveClaimsFunc   func(user string, provider string, claims []claim.Claim) (*claim.UserClaims, error)
+	AddUserFunc        func(user string, provider string) (string, error)
+	RemoveUserFunc     func(user string, provider string) (string, error)
+	ProvisionUsersFunc func(ctx context.Context, provider string, users []claim.UserProvisioning, roles []claim.Role) []claim.ProvisionResult
+	ReconcileFunc      func(ctx context.Context, desired claim.DesiredState, opts claim.ReconcileOptions) (*claim.Plan, error)
+	PlanFunc           func(ctx context.Context, desired claim.DesiredState) (*claim.Plan, error)
+	ApplyFunc          func(ctx context.Context, plan claim.Plan) error
+	ExportFunc         func(ctx context.Context, provider string, users []string) (*claim.Snapshot, error)
+	ImportFunc         func(ctx context.Context, snapshot claim.Snapshot, opts claim.ReconcileOptions) (*claim.Plan, error)
+}
+
+var _ claim.Client = (*Claim)(nil)
+
+func (m *Claim) GetClaim(user string, provider string) (string, error) {
+	m.record("GetClaim", user, provider)
+	if m.GetClaimFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.GetClaimFunc(user, provider)
+}
+
+func (m *Claim) GetUserClaims(user string, provider string) (*claim.UserClaims, error) {
+	m.record("GetUserClaims", user, provider)
+	if m.GetUserClaimsFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.GetUserClaimsFunc(user, provider)
+}
+
+func (m *Claim) AddClaim(user string, provider string, claims []string) (string, error) {
+	m.record("AddClaim", user, provider, claims)
+	if m.AddClaimFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.AddClaimFunc(user, provider, claims)
+}
+
+func (m *Claim) AddClaims(user string, provider string, claims []claim.Claim) (*claim.UserClaims, error) {
+	m.record("AddClaims", user, provider, claims)
+	if m.AddClaimsFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.AddClaimsFunc(user, provider, claims)
+}
+
+func (m *Claim) RemoveClaim(user string, provider string, claims []string) (string, error) {
+	m.record("RemoveClaim", user, provider, claims)
+	if m.RemoveClaimFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.RemoveClaimFunc(user, provider, claims)
+}
+
+func (m *Claim) RemoveClaims(user string, provider string, claims []claim.Claim) (*claim.UserClaims, error) {
+	m.record("RemoveClaims", user, provider, claims)
+	if m.RemoveClaimsFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.RemoveClaimsFunc(user, provider, claims)
+}
+
+func (m *Claim) AddUser(user string, provider string) (string, error) {
+	m.record("AddUser", user, provider)
+	if m.AddUserFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.AddUserFunc(user, provider)
+}
+
+func (m *Claim) RemoveUser(user string, provider string) (string, error) {
+	m.record("RemoveUser", user, provider)
+	if m.RemoveUserFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.RemoveUserFunc(user, provider)
+}
+
+func (m *Claim) ProvisionUsers(ctx context.Context, provider string, users []claim.UserProvisioning, roles []claim.Role) []claim.ProvisionResult {
+	m.record("ProvisionUsers", ctx, provider, users, roles)
+	if m.ProvisionUsersFunc == nil {
+		results := make([]claim.ProvisionResult, len(users))
+		for i, user := range users {
+			results[i] = claim.ProvisionResult{User: user.User, Err: ErrNotImplemented}
+		}
+		return results
+	}
+	return m.ProvisionUsersFunc(ctx, provider, users, roles)
+}
+
+func (m *Claim) Reconcile(ctx context.Context, desired claim.DesiredState, opts claim.ReconcileOptions) (*claim.Plan, error) {
+	m.record("Reconcile", ctx, desired, opts)
+	if m.ReconcileFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.ReconcileFunc(ctx, desired, opts)
+}
+
+func (m *Claim) Plan(ctx context.Context, desired claim.DesiredState) (*claim.Plan, error) {
+	m.record("Plan", ctx, desired)
+	if m.PlanFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.PlanFunc(ctx, desired)
+}
+
+func (m *Claim) Apply(ctx context.Context, plan claim.Plan) error {
+	m.record("Apply", ctx, plan)
+	if m.ApplyFunc == nil {
+		return ErrNotImplemented
+	}
+	return m.ApplyFunc(ctx, plan)
+}
+
+func (m *Claim) Export(ctx context.Context, provider string, users []string) (*claim.Snapshot, error) {
+	m.record("Export", ctx, provider, users)
+	if m.ExportFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.ExportFunc(ctx, provider, users)
+}
+
+func (m *Claim) Import(ctx context.Context, snapshot claim.Snapshot, opts claim.ReconcileOptions) (*claim.Plan, error) {
+	m.record("Import", ctx, snapshot, opts)
+	if m.ImportFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.ImportFunc(ctx, snapshot, opts)
+}
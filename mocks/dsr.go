@@ -0,0 +1,87 @@
+package mocks
+
+import (
+	"context"
+	"github.com/SneaksAndData/esd-services-api-client-go/dsr"
+	"io"
+	"time"
+)
+
+// DSR mocks dsr.Client, the DSR service.
+type DSR struct {
+	recorder
+	GetDSRRequestFunc       func(email string) (string, error)
+	GetDSRResultFunc        func(email string) (*dsr.DSRResult, error)
+	BatchLookupFunc         func(ctx context.Context, r io.Reader, opts dsr.BatchOptions) (*dsr.BatchSummary, error)
+	CreateRequestFunc       func(input dsr.NewRequest) (*dsr.Request, error)
+	GetRequestFunc          func(id string) (*dsr.Request, error)
+	ListRequestsFunc        func(status string) ([]dsr.Request, error)
+	ListPendingRequestsFunc func() ([]dsr.Request, error)
+	WaitForRequestFunc      func(ctx context.Context, id string, pollInterval time.Duration) (*dsr.Request, error)
+}
+
+var _ dsr.Client = (*DSR)(nil)
+
+func (m *DSR) GetDSRRequest(email string) (string, error) {
+	m.record("GetDSRRequest", email)
+	if m.GetDSRRequestFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.GetDSRRequestFunc(email)
+}
+
+func (m *DSR) GetDSRResult(email string) (*dsr.DSRResult, error) {
+	m.record("GetDSRResult", email)
+	if m.GetDSRResultFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.GetDSRResultFunc(email)
+}
+
+func (m *DSR) BatchLookup(ctx context.Context, r io.Reader, opts dsr.BatchOptions) (*dsr.BatchSummary, error) {
+	m.record("BatchLookup", ctx, r, opts)
+	if m.BatchLookupFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.BatchLookupFunc(ctx, r, opts)
+}
+
+func (m *DSR) CreateRequest(input dsr.NewRequest) (*dsr.Request, error) {
+	m.record("CreateRequest", input)
+	if m.CreateRequestFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.CreateRequestFunc(input)
+}
+
+func (m *DSR) GetRequest(id string) (*dsr.Request, error) {
+	m.record("GetRequest", id)
+	if m.GetRequestFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.GetRequestFunc(id)
+}
+
+func (m *DSR) ListRequests(status string) ([]dsr.Request, error) {
+	m.record("ListRequests", status)
+	if m.ListRequestsFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.ListRequestsFunc(status)
+}
+
+func (m *DSR) ListPendingRequests() ([]dsr.Request, error) {
+	m.record("ListPendingRequests")
+	if m.ListPendingRequestsFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.ListPendingRequestsFunc()
+}
+
+func (m *DSR) WaitForRequest(ctx context.Context, id string, pollInterval time.Duration) (*dsr.Request, error) {
+	m.record("WaitForRequest", ctx, id, pollInterval)
+	if m.WaitForRequestFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.WaitForRequestFunc(ctx, id, pollInterval)
+}
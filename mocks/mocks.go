@@ -0,0 +1,53 @@
+// Package mocks provides hand-written mocks of the service clients for unit tests.
+//
+// Each mock has a function field per method, i.e. RunJobFunc for RunJob, and records every call.
+// Calling a method whose function is not set fails with ErrNotImplemented: methods with an error result return it
+// with zero values, ProvisionUsers reports it for every user, and methods without a way to report errors,
+// i.e. Auth.Source, panic with it.
+package mocks
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotImplemented is returned, or panicked with, by mock methods whose function is not set.
+var ErrNotImplemented = errors.New("mock method not implemented")
+
+// Call is a call made to a mock.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// recorder stores the calls made to a mock and is safe for concurrent use.
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the recorded calls in the order they were made.
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallCount returns the number of calls made to method.
+func (r *recorder) CallCount(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, call := range r.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
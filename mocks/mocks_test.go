@@ -0,0 +1,152 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/SneaksAndData/esd-services-api-client-go/algorithm"
+	"github.com/SneaksAndData/esd-services-api-client-go/auth"
+	"github.com/SneaksAndData/esd-services-api-client-go/claim"
+	"github.com/SneaksAndData/esd-services-api-client-go/dsr"
+	"github.com/SneaksAndData/esd-services-api-client-go/spark"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpark(t *testing.T) {
+	var client spark.Client = &Spark{
+		RunJobFunc: func(request spark.JobParams, sparkJobName string) (string, error) {
+			return "submission-" + sparkJobName, nil
+		},
+	}
+
+	if id, err := client.RunJob(spark.JobParams{}, "job"); err != nil || id != "submission-job" {
+		t.Errorf("RunJob() = %q, %v", id, err)
+	}
+	if _, err := client.GetLogs("submission-job"); !errors.Is(err, ErrNotImplemented) {
+		t.Errorf("GetLogs() error = %v, want ErrNotImplemented", err)
+	}
+
+	mock := client.(*Spark)
+	calls := mock.Calls()
+	if len(calls) != 2 || calls[0].Method != "RunJob" || calls[0].Args[1] != "job" || mock.CallCount("GetLogs") != 1 {
+		t.Errorf("Calls() = %+v", calls)
+	}
+}
+
+func TestAlgorithm(t *testing.T) {
+	var client algorithm.Client = &Algorithm{
+		RetrieveRunsByTagFunc: func(tag string) ([]algorithm.TaggedRun, error) {
+			return []algorithm.TaggedRun{{AlgorithmName: "forecast"}}, nil
+		},
+	}
+
+	if runs, err := client.RetrieveRunsByTag("backfill"); err != nil || len(runs) != 1 || runs[0].AlgorithmName != "forecast" {
+		t.Errorf("RetrieveRunsByTag() = %+v, %v", runs, err)
+	}
+	if _, err := client.CancelRuns(context.Background(), algorithm.RunFilter{Tag: "backfill"}, "on-call", "test"); !errors.Is(err, ErrNotImplemented) {
+		t.Errorf("CancelRuns() error = %v, want ErrNotImplemented", err)
+	}
+	if calls := client.(*Algorithm).Calls(); len(calls) != 2 || calls[0].Args[0] != "backfill" {
+		t.Errorf("Calls() = %+v", calls)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	var client claim.Client = &Claim{
+		AddClaimsFunc: func(user string, provider string, claims []claim.Claim) (*claim.UserClaims, error) {
+			return &claim.UserClaims{UserID: user, IdentityProvider: provider, Claims: claims}, nil
+		},
+	}
+
+	if userClaims, err := client.AddClaims("alice", "azuread", []claim.Claim{{Path: "a", Pattern: ".*"}}); err != nil || len(userClaims.Claims) != 1 {
+		t.Errorf("AddClaims() = %+v, %v", userClaims, err)
+	}
+	results := client.ProvisionUsers(context.Background(), "azuread", []claim.UserProvisioning{{User: "alice"}, {User: "bob"}}, nil)
+	if len(results) != 2 || results[1].User != "bob" || !errors.Is(results[1].Err, ErrNotImplemented) {
+		t.Errorf("ProvisionUsers() = %+v, want ErrNotImplemented for every user", results)
+	}
+	if client.(*Claim).CallCount("ProvisionUsers") != 1 {
+		t.Errorf("CallCount(ProvisionUsers) = %d, want 1", client.(*Claim).CallCount("ProvisionUsers"))
+	}
+}
+
+func TestDSR(t *testing.T) {
+	var client dsr.Client = &DSR{
+		GetRequestFunc: func(id string) (*dsr.Request, error) {
+			return &dsr.Request{ID: id, Status: dsr.StatusCompleted}, nil
+		},
+	}
+
+	if request, err := client.GetRequest("request-1"); err != nil || !request.IsFinished() {
+		t.Errorf("GetRequest() = %+v, %v", request, err)
+	}
+	if _, err := client.WaitForRequest(context.Background(), "request-1", time.Second); !errors.Is(err, ErrNotImplemented) {
+		t.Errorf("WaitForRequest() error = %v, want ErrNotImplemented", err)
+	}
+	if calls := client.(*DSR).Calls(); len(calls) != 2 || calls[1].Args[2] != time.Second {
+		t.Errorf("Calls() = %+v", calls)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	var client auth.Client = &Auth{
+		GetBoxerTokenFunc: func() (string, error) { return "token", nil },
+	}
+
+	if token, err := client.GetBoxerToken(); err != nil || token != "token" {
+		t.Errorf("GetBoxerToken() = %q, %v", token, err)
+	}
+	defer func() {
+		if r := recover(); r != ErrNotImplemented {
+			t.Errorf("Source() panicked with %v, want ErrNotImplemented", r)
+		}
+		if client.(*Auth).CallCount("Source") != 1 {
+			t.Errorf("CallCount(Source) = %d, want 1", client.(*Auth).CallCount("Source"))
+		}
+	}()
+	client.Source()
+}
+
+// TestUnsetMethodsFail calls every method of every mock without setting its function.
+func TestUnsetMethodsFail(t *testing.T) {
+	for _, mock := range []interface{}{&Spark{}, &Algorithm{}, &Claim{}, &DSR{}, &Auth{}} {
+		value := reflect.ValueOf(mock)
+		for i := 0; i < value.NumMethod(); i++ {
+			method := value.Type().Method(i)
+			if method.Name == "Calls" || method.Name == "CallCount" {
+				continue
+			}
+			t.Run(value.Elem().Type().Name()+"."+method.Name, func(t *testing.T) {
+				args := make([]reflect.Value, method.Type.NumIn()-1)
+				for j := range args {
+					args[j] = reflect.Zero(method.Type.In(j + 1))
+				}
+				if method.Name == "ProvisionUsers" {
+					args[2] = reflect.ValueOf([]claim.UserProvisioning{{User: "alice"}})
+				}
+
+				var results []reflect.Value
+				panicked := func() (recovered interface{}) {
+					defer func() { recovered = recover() }()
+					results = value.Method(i).Call(args)
+					return nil
+				}()
+				switch {
+				case panicked != nil:
+					if panicked != ErrNotImplemented {
+						t.Errorf("panicked with %v, want ErrNotImplemented", panicked)
+					}
+				case method.Name == "ProvisionUsers":
+					if err := results[0].Index(0).FieldByName("Err").Interface(); err != ErrNotImplemented {
+						t.Errorf("result error = %v, want ErrNotImplemented", err)
+					}
+				default:
+					if err := results[len(results)-1].Interface(); err != ErrNotImplemented {
+						t.Errorf("error = %v, want ErrNotImplemented", err)
+					}
+				}
+			})
+		}
+	}
+}
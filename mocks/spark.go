@@ -0,0 +1,55 @@
+package mocks
+
+import "github.com/SneaksAndData/esd-services-api-client-go/spark"
+
+// Spark mocks spark.Client, the Beast service.
+type Spark struct {
+	recorder
+	RunJobFunc            func(request spark.JobParams, sparkJobName string) (string, error)
+	GetLifecycleStageFunc func(id string) (interface{}, error)
+	GetRuntimeInfoFunc    func(id string) (string, error)
+	GetConfigurationFunc  func(name string) (spark.SubmissionConfiguration, error)
+	GetLogsFunc           func(id string) (string, error)
+}
+
+var _ spark.Client = (*Spark)(nil)
+
+func (m *Spark) RunJob(request spark.JobParams, sparkJobName string) (string, error) {
+	m.record("RunJob", request, sparkJobName)
+	if m.RunJobFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.RunJobFunc(request, sparkJobName)
+}
+
+func (m *Spark) GetLifecycleStage(id string) (interface{}, error) {
+	m.record("GetLifecycleStage", id)
+	if m.GetLifecycleStageFunc == nil {
+		return nil, ErrNotImplemented
+	}
+	return m.GetLifecycleStageFunc(id)
+}
+
+func (m *Spark) GetRuntimeInfo(id string) (string, error) {
+	m.record("GetRuntimeInfo", id)
+	if m.GetRuntimeInfoFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.GetRuntimeInfoFunc(id)
+}
+
+func (m *Spark) GetConfiguration(name string) (spark.SubmissionConfiguration, error) {
+	m.record("GetConfiguration", name)
+	if m.GetConfigurationFunc == nil {
+		return spark.SubmissionConfiguration{}, ErrNotImplemented
+	}
+	return m.GetConfigurationFunc(name)
+}
+
+func (m *Spark) GetLogs(id string) (string, error) {
+	m.record("GetLogs", id)
+	if m.GetLogsFunc == nil {
+		return "", ErrNotImplemented
+	}
+	return m.GetLogsFunc(id)
+}
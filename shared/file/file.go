@@ -1,8 +1,28 @@
 package file
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+)
 
 func FileExists(filePath string) bool {
 	_, err := os.Stat(filePath)
 	return err == nil
 }
+
+// WriteAtomic writes content with 0600 permissions through a temporary file, so readers never see a partial file.
+func WriteAtomic(filePath string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
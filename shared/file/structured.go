@@ -0,0 +1,66 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsYAML reports whether the path has a YAML extension.
+func IsYAML(filePath string) bool {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ReadStructured decodes a JSON or YAML file into v, picking the format from the file extension.
+// YAML documents are converted to JSON first so that the json tags of v apply to both formats.
+func ReadStructured(filePath string, v interface{}) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading file %s: %w", filePath, err)
+	}
+	if IsYAML(filePath) {
+		content, err = YAMLToJSON(content)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", filePath, err)
+		}
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("error parsing %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// YAMLToJSON converts a YAML document into its JSON representation.
+func YAMLToJSON(content []byte) ([]byte, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(document)
+}
+
+// WriteStructured encodes v as JSON or YAML, picking the format from the file extension, and writes it with 0600 permissions.
+// YAML documents are produced from the JSON encoding so that the json tags of v apply to both formats.
+func WriteStructured(filePath string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling %s: %w", filePath, err)
+	}
+	if IsYAML(filePath) {
+		var document interface{}
+		if err := json.Unmarshal(content, &document); err != nil {
+			return fmt.Errorf("error marshaling %s: %w", filePath, err)
+		}
+		if content, err = yaml.Marshal(document); err != nil {
+			return fmt.Errorf("error marshaling %s: %w", filePath, err)
+		}
+	}
+	if err := os.WriteFile(filePath, content, 0o600); err != nil {
+		return fmt.Errorf("error writing file %s: %w", filePath, err)
+	}
+	return nil
+}
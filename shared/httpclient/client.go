@@ -10,6 +10,7 @@ package httpclient
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -21,6 +22,23 @@ import (
 type Client struct {
 	httpClient *http.Client
 	getToken   func() (string, error) // Function to get or refresh the token
+	invalidate func()                 // Function discarding cached tokens after an authorization failure, may be nil
+}
+
+// StatusError is returned when a request completes with an unexpected HTTP status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP request failed with status code: %d - %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err is caused by a 404 Not Found response.
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
 }
 
 // NewClient creates a new Client instance with a specified function for token retrieval.
@@ -31,6 +49,22 @@ func NewClient(getTokenFunc func() (string, error)) *Client {
 	}
 }
 
+// NewClientWithInvalidate creates a new Client that calls invalidateFunc when a request fails authorization,
+// before the token is retrieved again for the retry, so that token sources caching tokens return a fresh one.
+func NewClientWithInvalidate(getTokenFunc func() (string, error), invalidateFunc func()) *Client {
+	client := NewClient(getTokenFunc)
+	client.invalidate = invalidateFunc
+	return client
+}
+
+// ClientOrNew returns client when it is set, so services can share one Client, otherwise a new Client using getTokenFunc.
+func ClientOrNew(client *Client, getTokenFunc func() (string, error)) *Client {
+	if client != nil {
+		return client
+	}
+	return NewClient(getTokenFunc)
+}
+
 // MakeRequest creates and executes an HTTP request using the given method, URL, and payload.
 // It automatically handles token retrieval and will retry the request once if the token is expired.
 func (c *Client) MakeRequest(method, url string, payload interface{}) ([]byte, error) {
@@ -47,6 +81,9 @@ func (c *Client) MakeRequest(method, url string, payload interface{}) ([]byte, e
 	responseBody, err := c.doRequest(request)
 	if err != nil {
 		if err.Error() == "authorization failed" {
+			if c.invalidate != nil {
+				c.invalidate()
+			}
 			refreshedToken, err := c.getToken()
 			if err != nil {
 				return nil, err
@@ -109,7 +146,7 @@ func (c *Client) doRequest(req *http.Request) ([]byte, error) {
 	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
 		return nil, fmt.Errorf("authorization failed")
 	} else if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		return nil, fmt.Errorf("HTTP request failed with status code: %d - %s", resp.StatusCode, string(respBody))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	return respBody, nil
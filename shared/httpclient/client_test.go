@@ -0,0 +1,36 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMakeRequestInvalidatesRejectedToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	token := "stale"
+	invalidations := 0
+	client := NewClientWithInvalidate(func() (string, error) { return token, nil }, func() {
+		invalidations++
+		token = "fresh"
+	})
+	response, err := client.MakeRequest(http.MethodGet, server.URL, nil)
+	if err != nil || string(response) != "ok" || invalidations != 1 {
+		t.Errorf("MakeRequest() = %q, %v after %d invalidations", response, err, invalidations)
+	}
+
+	// Without an invalidate func the same token is retried once
+	token = "stale"
+	if _, err := NewClient(func() (string, error) { return token, nil }).MakeRequest(http.MethodGet, server.URL, nil); err == nil {
+		t.Errorf("MakeRequest() with a rejected token succeeded")
+	}
+}
@@ -0,0 +1,69 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL builds request URLs from a base URL, path segments and query parameters.
+// Path segments are escaped, so values such as emails with '+', '/', '#' or spaces stay a single segment.
+type URL struct {
+	base     string
+	segments []string
+	query    url.Values
+}
+
+// NewURL starts a URL from a base URL, see NormalizeBaseURL for the accepted forms.
+func NewURL(base string) *URL {
+	return &URL{base: normalize(base), query: url.Values{}}
+}
+
+// Path appends escaped path segments.
+func (u *URL) Path(segments ...string) *URL {
+	u.segments = append(u.segments, segments...)
+	return u
+}
+
+// Query adds an encoded query parameter.
+func (u *URL) Query(key string, value string) *URL {
+	u.query.Add(key, value)
+	return u
+}
+
+// String returns the assembled URL.
+func (u *URL) String() string {
+	var b strings.Builder
+	b.WriteString(u.base)
+	for _, segment := range u.segments {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(segment))
+	}
+	if len(u.query) > 0 {
+		b.WriteByte('?')
+		b.WriteString(u.query.Encode())
+	}
+	return b.String()
+}
+
+// NormalizeBaseURL validates a base URL, adding the https scheme when it is missing, i.e. "example.com",
+// and removing trailing slashes.
+func NormalizeBaseURL(base string) (string, error) {
+	normalized := normalize(base)
+	parsed, err := url.Parse(normalized)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %q: %w", base, err)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q: missing host", base)
+	}
+	return normalized, nil
+}
+
+func normalize(base string) string {
+	base = strings.TrimSpace(base)
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	return strings.TrimRight(base, "/")
+}
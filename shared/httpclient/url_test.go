@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name string
+		url  *URL
+		want string
+	}{
+		{
+			name: "Missing scheme and trailing slash",
+			url:  NewURL("example.com/").Path("claim", "azuread", "user@example.com"),
+			want: "https://example.com/claim/azuread/user@example.com",
+		},
+		{
+			name: "Base path is kept",
+			url:  NewURL("http://localhost:8080/api//").Path("dsr", "a"),
+			want: "http://localhost:8080/api/dsr/a",
+		},
+		{
+			name: "Special characters are escaped",
+			url:  NewURL("https://example.com").Path("dsr", "first last/#1+tag@example.com"),
+			want: "https://example.com/dsr/first%20last%2F%231+tag@example.com",
+		},
+		{
+			name: "Query parameters are encoded",
+			url:  NewURL("https://example.com").Path("search").Query("email", "a+b@example.com").Query("q", "x&y"),
+			want: "https://example.com/search?email=a%2Bb%40example.com&q=x%26y",
+		},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.url.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeBaseURL(t *testing.T) {
+	if _, err := NormalizeBaseURL(""); err == nil {
+		t.Errorf("NormalizeBaseURL(\"\") succeeded")
+	}
+	if got, err := NormalizeBaseURL("example.com/"); err != nil || got != "https://example.com" {
+		t.Errorf("NormalizeBaseURL() = %v, %v", got, err)
+	}
+}
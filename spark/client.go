@@ -0,0 +1,12 @@
+package spark
+
+// Client is implemented by Service, so code depending on Beast can be tested with a mock, see the mocks package.
+type Client interface {
+	RunJob(request JobParams, sparkJobName string) (string, error)
+	GetLifecycleStage(id string) (interface{}, error)
+	GetRuntimeInfo(id string) (string, error)
+	GetConfiguration(name string) (SubmissionConfiguration, error)
+	GetLogs(id string) (string, error)
+}
+
+var _ Client = (*Service)(nil)
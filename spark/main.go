@@ -4,6 +4,7 @@ package spark
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/environment"
 	"github.com/SneaksAndData/esd-services-api-client-go/shared/httpclient"
 	"golang.org/x/exp/slices"
 	"log"
@@ -126,7 +127,7 @@ func (s Service) RunJob(request JobParams, sparkJobName string) (string, error)
 
 func (s Service) submitJob(request JobRequest, sparkJobName string) (submission, error) {
 	log.Printf("Submitting request: %+v", request)
-	targetURL := fmt.Sprintf("%s/job/submit/%s", s.baseURL, sparkJobName)
+	targetURL := httpclient.NewURL(s.baseURL).Path("job", "submit", sparkJobName).String()
 	result, err := s.httpClient.MakeRequest(http.MethodPost, targetURL, request)
 	if err != nil {
 		return submission{}, fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -144,7 +145,7 @@ func (s Service) submitJob(request JobRequest, sparkJobName string) (submission,
 
 func (s Service) checkExistingSubmission(tag string) (string, error) {
 	log.Printf("Looking for existing submission of %s", tag)
-	targetURL := fmt.Sprintf("%s/job/requests/tags/%s", s.baseURL, tag)
+	targetURL := httpclient.NewURL(s.baseURL).Path("job", "requests", "tags", tag).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -165,7 +166,7 @@ func (s Service) checkExistingSubmission(tag string) (string, error) {
 		if err != nil {
 			return "", fmt.Errorf("error getting lifecycle stage for %s: %w", id, err)
 		}
-		if !slices.Contains(successStages, stage.(string)) && !slices.Contains(failedStages, stage.(string)) {
+		if !IsSuccessStage(stage.(string)) && !IsFailedStage(stage.(string)) {
 			log.Printf("Found a running submission of %s: %s", tag, id)
 			runningSubmissions = append(runningSubmissions, submission{ID: id, Stage: stage.(string)})
 		}
@@ -187,13 +188,23 @@ func (s Service) checkExistingSubmission(tag string) (string, error) {
 	return string(run), err
 }
 
+// IsSuccessStage reports whether a lifecycle stage marks a successfully completed submission.
+func IsSuccessStage(stage string) bool {
+	return slices.Contains(successStages, stage)
+}
+
+// IsFailedStage reports whether a lifecycle stage marks a failed submission.
+func IsFailedStage(stage string) bool {
+	return slices.Contains(failedStages, stage)
+}
+
 // GetLifecycleStage returns the lifecycle stage for a given request
 //
 // Parameters:
 //
 // - id: A request identifier to read lifecycle stage info for
 func (s Service) GetLifecycleStage(id string) (interface{}, error) {
-	targetURL := fmt.Sprintf("%s/job/requests/%s", s.baseURL, id)
+	targetURL := httpclient.NewURL(s.baseURL).Path("job", "requests", id).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -211,7 +222,7 @@ func (s Service) GetLifecycleStage(id string) (interface{}, error) {
 //
 // - id: A request identifier to read runtime info for
 func (s Service) GetRuntimeInfo(id string) (string, error) {
-	targetURL := fmt.Sprintf("%s/job/requests/%s", s.baseURL, id)
+	targetURL := httpclient.NewURL(s.baseURL).Path("job", "requests", id).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -225,7 +236,7 @@ func (s Service) GetRuntimeInfo(id string) (string, error) {
 //
 // - name: Name of the configuration to find
 func (s Service) GetConfiguration(name string) (SubmissionConfiguration, error) {
-	targetURL := fmt.Sprintf("%s/job/deployed/%s", s.baseURL, name)
+	targetURL := httpclient.NewURL(s.baseURL).Path("job", "deployed", name).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		fmt.Println(err)
@@ -244,7 +255,7 @@ func (s Service) GetConfiguration(name string) (SubmissionConfiguration, error)
 //
 // - id: Submission request identifier
 func (s Service) GetLogs(id string) (string, error) {
-	targetURL := fmt.Sprintf("%s/job/logs/%s", s.baseURL, id)
+	targetURL := httpclient.NewURL(s.baseURL).Path("job", "logs", id).String()
 	response, err := s.httpClient.MakeRequest(http.MethodGet, targetURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("error making request to %s: %w", targetURL, err)
@@ -260,15 +271,24 @@ func (s Service) GetLogs(id string) (string, error) {
 // Config represents the configuration needed to create a new spark Service instance.
 type Config struct {
 	BaseURL      string
+	Env          string // Environment whose Beast URL is used when BaseURL is empty, see the environment package
 	GetTokenFunc func() (string, error)
-	HTTPClient   *httpclient.Client
+	HTTPClient   *httpclient.Client // Shared HTTP client, GetTokenFunc is ignored when it is set
 }
 
 // New creates a new instance of the spark Service using the provided Config.
 func New(c Config) (*Service, error) {
+	baseURL, err := environment.Resolve(c.BaseURL, c.Env, func(p environment.Profile) string { return p.BeastURL })
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err = httpclient.NormalizeBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
 	s := &Service{
-		httpClient: httpclient.NewClient(c.GetTokenFunc),
-		baseURL:    c.BaseURL,
+		httpClient: httpclient.ClientOrNew(c.HTTPClient, c.GetTokenFunc),
+		baseURL:    baseURL,
 	}
 	return s, nil
 }
@@ -0,0 +1,315 @@
+// Package workflow runs dependent Beast jobs and Crystal runs as a single resumable flow
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/algorithm"
+	"github.com/SneaksAndData/esd-services-api-client-go/spark"
+	"time"
+)
+
+const (
+	defaultConcurrency  = 4
+	defaultPollInterval = 30 * time.Second
+)
+
+// SparkClient is the subset of spark.Client used to run Beast steps.
+type SparkClient interface {
+	RunJob(request spark.JobParams, sparkJobName string) (string, error)
+	GetLifecycleStage(id string) (interface{}, error)
+}
+
+// AlgorithmClient is the subset of algorithm.Client used to run Crystal steps.
+type AlgorithmClient interface {
+	CreateRun(algorithmName string, input algorithm.Payload, tag string) (string, error)
+	RetrieveRun(runID string, algorithmName string) (string, error)
+}
+
+// Step is a single unit of work. Exactly one of Spark and Crystal must be set.
+type Step struct {
+	Name      string
+	DependsOn []string
+	// Inputs injects outputs of upstream steps into this step's parameters, keyed by parameter name.
+	// Crystal steps receive them as AlgorithmParameters, Spark steps as ExtraArguments.
+	Inputs  map[string]Output
+	Spark   *SparkStep
+	Crystal *CrystalStep
+}
+
+// SparkStep submits a Beast job. Its outputs are the data paths of ProjectOutputs, keyed by alias.
+type SparkStep struct {
+	JobName string
+	Params  spark.JobParams
+}
+
+// CrystalStep submits a Crystal run. Its outputs are "requestId" and "resultUri".
+type CrystalStep struct {
+	AlgorithmName string
+	Payload       algorithm.Payload
+	Tag           string
+}
+
+// Output references a named output of another step.
+type Output struct {
+	Step string
+	Name string
+}
+
+// Config holds the configuration needed to create a new Workflow.
+type Config struct {
+	Spark        SparkClient     // Client for Spark steps, required when the workflow has any
+	Algorithm    AlgorithmClient // Client for Crystal steps, required when the workflow has any
+	Concurrency  int             // Maximum number of steps running at the same time, defaults to 4
+	PollInterval time.Duration   // Interval between status checks of running steps, defaults to 30s
+	StatePath    string          // Optional file the state is persisted to after every transition
+}
+
+// Workflow executes steps in dependency order.
+type Workflow struct {
+	config Config
+	steps  []Step
+	state  *State
+}
+
+// New validates the step graph and, when StatePath points to an existing file, restores the state from it.
+func New(c Config, steps ...Step) (*Workflow, error) {
+	if c.Concurrency <= 0 {
+		c.Concurrency = defaultConcurrency
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultPollInterval
+	}
+	if err := validateSteps(c, steps); err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(c.StatePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		if _, ok := state.Steps[step.Name]; !ok {
+			state.Steps[step.Name] = &StepState{Status: StatusPending}
+		}
+	}
+	return &Workflow{config: c, steps: steps, state: state}, nil
+}
+
+// State returns the current state of the workflow.
+func (w *Workflow) State() *State {
+	return w.state
+}
+
+// Run executes all steps that have not succeeded yet. Steps still running from a previous
+// attempt are awaited instead of being submitted again. Steps whose dependencies failed are skipped.
+// When ctx is cancelled, running steps keep their state so that a later Run resumes them.
+// A step is persisted as running with its run identifier as soon as it is submitted, so a crashed Run resumes it too.
+func (w *Workflow) Run(ctx context.Context) error {
+	stepCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan stepResult)
+	launched := make(map[string]bool)
+	running := 0
+	// abort stops the running steps and waits for them, so no goroutine is left blocked on results.
+	abort := func(err error) error {
+		cancel()
+		for running > 0 {
+			if result := <-results; result.final {
+				running--
+			}
+		}
+		return err
+	}
+
+	for {
+		if err := w.skipBlocked(); err != nil {
+			return abort(err)
+		}
+		for _, step := range w.steps {
+			if running >= w.config.Concurrency || ctx.Err() != nil {
+				break
+			}
+			if launched[step.Name] || !w.isReady(step) {
+				continue
+			}
+			launched[step.Name] = true
+			running++
+			current := *w.state.Steps[step.Name]
+			inputs := w.resolveInputs(step)
+			go func(step Step) {
+				progress := func(st StepState) {
+					results <- stepResult{name: step.Name, state: st}
+				}
+				results <- stepResult{name: step.Name, state: w.execute(stepCtx, step, current, inputs, progress), final: true}
+			}(step)
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		if result.final {
+			running--
+		}
+		if err := w.update(result.name, result.state); err != nil {
+			return abort(err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return w.state.err()
+}
+
+// stepResult is a state transition of a step. Only the final one means the step stopped executing.
+type stepResult struct {
+	name  string
+	state StepState
+	final bool
+}
+
+// isReady reports whether the step still has to run and all of its dependencies succeeded.
+func (w *Workflow) isReady(step Step) bool {
+	status := w.state.Steps[step.Name].Status
+	if status == StatusSucceeded || status == StatusSkipped {
+		return false
+	}
+	for _, dependency := range step.DependsOn {
+		if w.state.Steps[dependency].Status != StatusSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// skipBlocked marks pending steps whose dependencies can no longer succeed as skipped.
+func (w *Workflow) skipBlocked() error {
+	for changed := true; changed; {
+		changed = false
+		for _, step := range w.steps {
+			if w.state.Steps[step.Name].Status != StatusPending {
+				continue
+			}
+			for _, dependency := range step.DependsOn {
+				status := w.state.Steps[dependency].Status
+				if status == StatusFailed || status == StatusSkipped {
+					if err := w.update(step.Name, StepState{Status: StatusSkipped, Error: fmt.Sprintf("dependency %s did not succeed", dependency)}); err != nil {
+						return err
+					}
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolveInputs collects the upstream outputs a step consumes.
+func (w *Workflow) resolveInputs(step Step) map[string]interface{} {
+	inputs := make(map[string]interface{}, len(step.Inputs))
+	for parameter, output := range step.Inputs {
+		inputs[parameter] = w.state.Steps[output.Step].Outputs[output.Name]
+	}
+	return inputs
+}
+
+// update records a state transition and persists it.
+func (w *Workflow) update(name string, st StepState) error {
+	w.state.Steps[name] = &st
+	if err := w.state.save(w.config.StatePath); err != nil {
+		return fmt.Errorf("error persisting workflow state: %w", err)
+	}
+	return nil
+}
+
+// validateSteps checks that step names are unique, every step has exactly one kind and a matching client,
+// and that dependencies and inputs reference known steps without forming a cycle.
+func validateSteps(c Config, steps []Step) error {
+	var errs []error
+	byName := make(map[string]Step, len(steps))
+	for _, step := range steps {
+		if step.Name == "" {
+			errs = append(errs, fmt.Errorf("step name is required"))
+			continue
+		}
+		if _, ok := byName[step.Name]; ok {
+			errs = append(errs, fmt.Errorf("duplicate step %s", step.Name))
+		}
+		byName[step.Name] = step
+
+		switch {
+		case (step.Spark == nil) == (step.Crystal == nil):
+			errs = append(errs, fmt.Errorf("step %s must define exactly one of Spark and Crystal", step.Name))
+		case step.Spark != nil && c.Spark == nil:
+			errs = append(errs, fmt.Errorf("step %s requires a Spark client", step.Name))
+		case step.Crystal != nil && c.Algorithm == nil:
+			errs = append(errs, fmt.Errorf("step %s requires an Algorithm client", step.Name))
+		}
+	}
+
+	for _, step := range steps {
+		for _, dependency := range step.DependsOn {
+			if _, ok := byName[dependency]; !ok {
+				errs = append(errs, fmt.Errorf("step %s depends on unknown step %s", step.Name, dependency))
+			}
+		}
+		for parameter, output := range step.Inputs {
+			if !dependsOn(byName, step, output.Step) {
+				errs = append(errs, fmt.Errorf("step %s input %s references %s which is not an upstream step", step.Name, parameter, output.Step))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	visiting := make(map[string]bool)
+	visited := make(map[string]bool)
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visiting[name] {
+			return fmt.Errorf("dependency cycle through step %s", name)
+		}
+		if visited[name] {
+			return nil
+		}
+		visiting[name] = true
+		for _, dependency := range byName[name].DependsOn {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		visited[name] = true
+		return nil
+	}
+	for _, step := range steps {
+		if err := visit(step.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependsOn reports whether upstream is a direct or transitive dependency of step.
+func dependsOn(byName map[string]Step, step Step, upstream string) bool {
+	seen := make(map[string]bool)
+	queue := append([]string{}, step.DependsOn...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if name == upstream {
+			return true
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		queue = append(queue, byName[name].DependsOn...)
+	}
+	return false
+}
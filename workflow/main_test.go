@@ -0,0 +1,301 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/algorithm"
+	"github.com/SneaksAndData/esd-services-api-client-go/spark"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSpark struct {
+	mu        sync.Mutex
+	submitted []spark.JobParams
+	stage     string // Stage reported for every submission, COMPLETED when empty
+	polls     int
+}
+
+func (f *fakeSpark) RunJob(request spark.JobParams, sparkJobName string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submitted = append(f.submitted, request)
+	return fmt.Sprintf("%s-%d", sparkJobName, len(f.submitted)), nil
+}
+
+func (f *fakeSpark) GetLifecycleStage(id string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.polls++
+	if f.stage == "" {
+		return "COMPLETED", nil
+	}
+	return f.stage, nil
+}
+
+func (f *fakeSpark) setStage(stage string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stage = stage
+}
+
+func (f *fakeSpark) pollCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.polls
+}
+
+type fakeAlgorithm struct {
+	mu         sync.Mutex
+	submitted  map[string]algorithm.Payload
+	created    int
+	fail       string
+	pollErrors int // Number of RetrieveRun calls that fail before runs are reported
+}
+
+func (f *fakeAlgorithm) CreateRun(algorithmName string, input algorithm.Payload, tag string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submitted[algorithmName] = input
+	f.created++
+	return fmt.Sprintf(`{"requestId": "%s"}`, algorithmName), nil
+}
+
+func (f *fakeAlgorithm) RetrieveRun(runID string, algorithmName string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pollErrors > 0 {
+		f.pollErrors--
+		return "", fmt.Errorf("error making request to https://crystal/%s: connection reset", runID)
+	}
+	if algorithmName == f.fail {
+		return fmt.Sprintf(`{"requestId": "%s", "status": "FAILED", "runErrorMessage": "boom"}`, runID), nil
+	}
+	return fmt.Sprintf(`{"requestId": "%s", "status": "COMPLETED", "resultUri": "https://results/%s"}`, runID, runID), nil
+}
+
+func testSteps() []Step {
+	return []Step{
+		{
+			Name: "prepare",
+			Spark: &SparkStep{JobName: "prepare", Params: spark.JobParams{
+				ProjectOutputs: []spark.JobSocket{{Alias: "features", DataPath: "abfss://features"}},
+			}},
+		},
+		{
+			Name:      "forecast",
+			DependsOn: []string{"prepare"},
+			Inputs:    map[string]Output{"featuresPath": {Step: "prepare", Name: "features"}},
+			Crystal:   &CrystalStep{AlgorithmName: "forecast", Payload: algorithm.Payload{AlgorithmParameters: map[string]interface{}{"horizon": 7}}},
+		},
+		{
+			Name:      "aggregate",
+			DependsOn: []string{"forecast"},
+			Inputs:    map[string]Output{"forecast": {Step: "forecast", Name: "resultUri"}},
+			Spark:     &SparkStep{JobName: "aggregate"},
+		},
+	}
+}
+
+func TestWorkflowRun(t *testing.T) {
+	sparkClient := &fakeSpark{}
+	algorithmClient := &fakeAlgorithm{submitted: make(map[string]algorithm.Payload)}
+	w, err := New(Config{Spark: sparkClient, Algorithm: algorithmClient, PollInterval: time.Millisecond}, testSteps()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := algorithmClient.submitted["forecast"].AlgorithmParameters["featuresPath"]; got != "abfss://features" {
+		t.Errorf("forecast featuresPath = %v, want abfss://features", got)
+	}
+	if got := sparkClient.submitted[1].ExtraArguments["forecast"]; got != "https://results/forecast" {
+		t.Errorf("aggregate forecast = %v, want https://results/forecast", got)
+	}
+}
+
+func TestWorkflowResume(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	sparkClient := &fakeSpark{}
+	algorithmClient := &fakeAlgorithm{submitted: make(map[string]algorithm.Payload), fail: "forecast"}
+	config := Config{Spark: sparkClient, Algorithm: algorithmClient, PollInterval: time.Millisecond, StatePath: statePath}
+
+	w, err := New(config, testSteps()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Run(context.Background()); err == nil {
+		t.Fatal("Run() with failing step succeeded")
+	}
+	if got := w.State().Steps["aggregate"].Status; got != StatusSkipped {
+		t.Errorf("aggregate status = %s, want %s", got, StatusSkipped)
+	}
+
+	algorithmClient.fail = ""
+	resumed, err := New(config, testSteps()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.Run(context.Background()); err != nil {
+		t.Fatalf("resumed Run() error = %v", err)
+	}
+	if len(sparkClient.submitted) != 2 {
+		t.Errorf("spark submissions = %d, want 2 (prepare must not run again)", len(sparkClient.submitted))
+	}
+}
+
+func TestNewRejectsCycles(t *testing.T) {
+	steps := []Step{
+		{Name: "a", DependsOn: []string{"b"}, Spark: &SparkStep{JobName: "a"}},
+		{Name: "b", DependsOn: []string{"a"}, Spark: &SparkStep{JobName: "b"}},
+	}
+	if _, err := New(Config{Spark: &fakeSpark{}}, steps...); err == nil {
+		t.Error("New() with a dependency cycle succeeded")
+	}
+}
+
+func TestWorkflowPersistsRunningSteps(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	sparkClient := &fakeSpark{stage: "RUNNING"}
+	steps := []Step{{Name: "prepare", Spark: &SparkStep{JobName: "prepare"}}}
+	w, err := New(Config{Spark: sparkClient, PollInterval: time.Millisecond, StatePath: statePath}, steps...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- w.Run(context.Background()) }()
+
+	// The step is still polling, the state file must already identify the run.
+	var state State
+	deadline := time.Now().Add(5 * time.Second)
+	for sparkClient.pollCount() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("step was not polled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	content, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("state file while polling: %v", err)
+	}
+	if err := json.Unmarshal(content, &state); err != nil {
+		t.Fatal(err)
+	}
+	if got := state.Steps["prepare"]; got == nil || got.Status != StatusRunning || got.RunID != "prepare-1" {
+		t.Errorf("persisted state = %+v, want RUNNING with run prepare-1", got)
+	}
+
+	sparkClient.setStage("COMPLETED")
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// A workflow restarted from the state persisted mid-step resumes the run instead of submitting again.
+	crashPath := filepath.Join(t.TempDir(), "crashed.json")
+	if err := os.WriteFile(crashPath, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := New(Config{Spark: sparkClient, PollInterval: time.Millisecond, StatePath: crashPath}, steps...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.Run(context.Background()); err != nil || len(sparkClient.submitted) != 1 {
+		t.Errorf("resumed Run() error = %v, submissions = %d, want 1", err, len(sparkClient.submitted))
+	}
+}
+
+func TestWorkflowRetriesFailedStatusChecks(t *testing.T) {
+	algorithmClient := &fakeAlgorithm{submitted: make(map[string]algorithm.Payload), pollErrors: 1}
+	steps := []Step{{Name: "forecast", Crystal: &CrystalStep{AlgorithmName: "forecast"}}}
+	w, err := New(Config{Algorithm: algorithmClient, PollInterval: time.Millisecond}, steps...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if algorithmClient.created != 1 {
+		t.Errorf("algorithm submissions = %d, want 1", algorithmClient.created)
+	}
+}
+
+func TestWorkflowResumesStepsWhoseStatusCouldNotBeChecked(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	algorithmClient := &fakeAlgorithm{submitted: make(map[string]algorithm.Payload), pollErrors: maxPollErrors}
+	steps := []Step{{Name: "forecast", Crystal: &CrystalStep{AlgorithmName: "forecast"}}}
+	config := Config{Algorithm: algorithmClient, PollInterval: time.Millisecond, StatePath: statePath}
+
+	w, err := New(config, steps...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Run(context.Background()); err == nil {
+		t.Fatal("Run() with an unreachable scheduler succeeded")
+	}
+	if got := w.State().Steps["forecast"]; got.Status != StatusFailed || got.RunID != "forecast" {
+		t.Errorf("forecast state = %+v, want FAILED with run forecast", got)
+	}
+
+	resumed, err := New(config, steps...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.Run(context.Background()); err != nil {
+		t.Fatalf("resumed Run() error = %v", err)
+	}
+	if algorithmClient.created != 1 {
+		t.Errorf("algorithm submissions = %d, want 1 (the existing run must be resumed)", algorithmClient.created)
+	}
+}
+
+func TestWorkflowStopsStepsWhenStateCannotBePersisted(t *testing.T) {
+	sparkClient := &fakeSpark{stage: "RUNNING"}
+	statePath := filepath.Join(t.TempDir(), "missing", "state.json")
+	steps := []Step{
+		{Name: "a", Spark: &SparkStep{JobName: "a"}},
+		{Name: "b", Spark: &SparkStep{JobName: "b"}},
+	}
+	w, err := New(Config{Spark: sparkClient, PollInterval: time.Millisecond, StatePath: statePath}, steps...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Run(context.Background()); err == nil {
+		t.Fatal("Run() with an unwritable state file succeeded")
+	}
+	// Every step goroutine has returned, so polling has stopped.
+	polls := sparkClient.pollCount()
+	time.Sleep(20 * time.Millisecond)
+	if sparkClient.pollCount() != polls {
+		t.Errorf("steps kept polling after Run returned")
+	}
+}
+
+func TestSubmissionID(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{name: "New submission", response: "abc-123", want: "abc-123"},
+		{name: "Existing submission", response: `{"ID":"abc-123","Stage":"RUNNING"}`, want: "abc-123"},
+	}
+
+	// Run test cases
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := submissionID(tt.response); got != tt.want {
+				t.Errorf("submissionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
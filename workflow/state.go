@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/shared/file"
+	"os"
+	"sort"
+)
+
+// Step lifecycle statuses.
+const (
+	StatusPending   = "PENDING"
+	StatusRunning   = "RUNNING"
+	StatusSucceeded = "SUCCEEDED"
+	StatusFailed    = "FAILED"
+	StatusSkipped   = "SKIPPED"
+)
+
+// State is the persisted progress of a workflow.
+type State struct {
+	Steps map[string]*StepState `json:"steps"`
+}
+
+// StepState is the persisted progress of a single step. A failed step keeps its RunID only when
+// the run was not seen finishing, so a resumed workflow checks that run before submitting again.
+type StepState struct {
+	Status  string            `json:"status"`
+	RunID   string            `json:"runId,omitempty"`
+	Outputs map[string]string `json:"outputs,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// loadState reads the state file, if any. Failed and skipped steps are reset so that they run again,
+// except failed steps that still have a run, which are resumed.
+func loadState(path string) (*State, error) {
+	state := &State{Steps: make(map[string]*StepState)}
+	if path == "" {
+		return state, nil
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading workflow state %s: %w", path, err)
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("error unmarshaling workflow state %s: %w", path, err)
+	}
+	if state.Steps == nil {
+		state.Steps = make(map[string]*StepState)
+	}
+	for _, st := range state.Steps {
+		switch {
+		case st.Status == StatusFailed && st.RunID != "":
+			*st = StepState{Status: StatusRunning, RunID: st.RunID}
+		case st.Status == StatusFailed || st.Status == StatusSkipped:
+			*st = StepState{Status: StatusPending}
+		}
+	}
+	return state, nil
+}
+
+// save writes the state atomically so that a crash never leaves a truncated file behind.
+func (s *State) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return file.WriteAtomic(path, content)
+}
+
+// err aggregates the errors of failed and skipped steps.
+func (s *State) err() error {
+	names := make([]string, 0, len(s.Steps))
+	for name := range s.Steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		st := s.Steps[name]
+		if st.Status == StatusFailed || st.Status == StatusSkipped {
+			errs = append(errs, fmt.Errorf("step %s %s: %s", name, st.Status, st.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,147 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/SneaksAndData/esd-services-api-client-go/algorithm"
+	"github.com/SneaksAndData/esd-services-api-client-go/spark"
+	"time"
+)
+
+// maxPollErrors is the number of consecutive failed status checks after which a step is given up on.
+const maxPollErrors = 5
+
+// execute submits the step, unless it already has a run, reports the running state through progress
+// and waits for the step to finish. Failed status checks are retried, only a finished run fails the step.
+// When ctx is cancelled or the status cannot be checked, the returned state keeps the run identifier
+// so that the step can be resumed.
+func (w *Workflow) execute(ctx context.Context, step Step, current StepState, inputs map[string]interface{}, progress func(StepState)) StepState {
+	if current.RunID == "" {
+		runID, err := w.submit(step, inputs)
+		if err != nil {
+			return StepState{Status: StatusFailed, Error: err.Error()}
+		}
+		current = StepState{Status: StatusRunning, RunID: runID}
+		progress(current)
+	}
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+	pollErrors := 0
+	for {
+		outputs, finished, err := w.poll(step, current.RunID)
+		switch {
+		case finished && err != nil:
+			return StepState{Status: StatusFailed, Error: err.Error()}
+		case finished:
+			return StepState{Status: StatusSucceeded, RunID: current.RunID, Outputs: outputs}
+		case err != nil:
+			if pollErrors++; pollErrors >= maxPollErrors {
+				return StepState{Status: StatusFailed, RunID: current.RunID, Error: err.Error()}
+			}
+		default:
+			pollErrors = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return current
+		case <-ticker.C:
+		}
+	}
+}
+
+// submit starts the step with upstream outputs injected into its parameters and returns the run identifier.
+func (w *Workflow) submit(step Step, inputs map[string]interface{}) (string, error) {
+	if step.Spark != nil {
+		params := step.Spark.Params
+		params.ExtraArguments = withInputs(params.ExtraArguments, inputs)
+		response, err := w.config.Spark.RunJob(params, step.Spark.JobName)
+		if err != nil {
+			return "", fmt.Errorf("error submitting spark job %s: %w", step.Spark.JobName, err)
+		}
+		return submissionID(response), nil
+	}
+
+	payload := step.Crystal.Payload
+	payload.AlgorithmParameters = withInputs(payload.AlgorithmParameters, inputs)
+	response, err := w.config.Algorithm.CreateRun(step.Crystal.AlgorithmName, payload, step.Crystal.Tag)
+	if err != nil {
+		return "", fmt.Errorf("error submitting algorithm %s: %w", step.Crystal.AlgorithmName, err)
+	}
+	var run algorithm.RunResult
+	if err := json.Unmarshal([]byte(response), &run); err != nil {
+		return "", fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	if run.RequestID == "" {
+		return "", fmt.Errorf("algorithm %s did not return a request id: %s", step.Crystal.AlgorithmName, response)
+	}
+	return run.RequestID, nil
+}
+
+// submissionID returns the identifier RunJob returned. When a submission with the same client tag
+// is already running, RunJob returns that submission encoded as JSON instead of a plain identifier.
+func submissionID(response string) string {
+	var existing struct {
+		ID string
+	}
+	if err := json.Unmarshal([]byte(response), &existing); err == nil && existing.ID != "" {
+		return existing.ID
+	}
+	return response
+}
+
+// poll checks the step once and reports whether its run has finished. A finished run returns its outputs
+// when it completed successfully and an error otherwise. An error for an unfinished run means the check failed.
+func (w *Workflow) poll(step Step, runID string) (map[string]string, bool, error) {
+	if step.Spark != nil {
+		stage, err := w.config.Spark.GetLifecycleStage(runID)
+		if err != nil {
+			return nil, false, err
+		}
+		stageName, _ := stage.(string)
+		switch {
+		case spark.IsFailedStage(stageName):
+			return nil, true, fmt.Errorf("spark job %s finished with stage %s", runID, stageName)
+		case !spark.IsSuccessStage(stageName):
+			return nil, false, nil
+		}
+		outputs := make(map[string]string, len(step.Spark.Params.ProjectOutputs))
+		for _, socket := range step.Spark.Params.ProjectOutputs {
+			outputs[socket.Alias] = socket.DataPath
+		}
+		return outputs, true, nil
+	}
+
+	response, err := w.config.Algorithm.RetrieveRun(runID, step.Crystal.AlgorithmName)
+	if err != nil {
+		return nil, false, err
+	}
+	var run algorithm.RunResult
+	if err := json.Unmarshal([]byte(response), &run); err != nil {
+		return nil, false, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	switch {
+	case !run.IsFinished():
+		return nil, false, nil
+	case run.Status != algorithm.StatusCompleted:
+		return nil, true, fmt.Errorf("algorithm run %s finished with status %s: %s", runID, run.Status, run.RunErrorMessage)
+	}
+	return map[string]string{"requestId": runID, "resultUri": run.ResultUri}, true, nil
+}
+
+// withInputs returns a copy of parameters with inputs added on top.
+func withInputs(parameters map[string]interface{}, inputs map[string]interface{}) map[string]interface{} {
+	if len(inputs) == 0 {
+		return parameters
+	}
+	merged := make(map[string]interface{}, len(parameters)+len(inputs))
+	for k, v := range parameters {
+		merged[k] = v
+	}
+	for k, v := range inputs {
+		merged[k] = v
+	}
+	return merged
+}